@@ -0,0 +1,64 @@
+package integration_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/paketo-buildpacks/php/integration/internal/harness"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testBudgets(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+	)
+
+	servers := matrixServers()
+
+	for _, budget := range budgets.Budgets {
+		if !budget.AppliesTo(builder.LocalInfo.Stack.ID) {
+			continue
+		}
+
+		context(fmt.Sprintf("building %s with the %s server", budget.Fixture, budget.Server), func() {
+			var fixture *harness.Fixture
+
+			it.Before(func() {
+				var err error
+				fixture, err = harness.NewFixture(phpBuildpack, filepath.Join("testdata", budget.Fixture))
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			it.After(func() {
+				Expect(fixture.Cleanup()).To(Succeed())
+			})
+
+			it("stays within its image size and build time budget", func() {
+				index := slices.IndexFunc(servers, func(s server) bool { return s.name == budget.Server })
+				Expect(index).NotTo(Equal(-1), fmt.Sprintf("budget names unknown server %q", budget.Server))
+
+				start := time.Now()
+				image, logs, err := fixture.Build().
+					WithEnv(servers[index].buildEnv(budget.Env)).
+					Execute()
+				buildTime := time.Since(start)
+				Expect(err).NotTo(HaveOccurred(), logs.String())
+
+				size, err := harness.ImageSize(image)
+				Expect(err).NotTo(HaveOccurred())
+
+				result := budgetReport.Record(budget, harness.Measurement{
+					ImageSizeMB:      float64(size) / 1e6,
+					BuildTimeSeconds: buildTime.Seconds(),
+				})
+				Expect(result.Exceeded).To(BeEmpty(), strings.Join(result.Exceeded, "\n"))
+			})
+		})
+	}
+}
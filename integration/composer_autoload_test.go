@@ -0,0 +1,80 @@
+package integration_test
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/occam"
+	"github.com/paketo-buildpacks/php/integration/internal/harness"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+	. "github.com/paketo-buildpacks/occam/matchers"
+)
+
+func testComposerAutoload(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+	)
+
+	// readVendorFile prints a file from the vendor directory of the given image
+	readVendorFile := func(image occam.Image, path string) string {
+		output, err := exec.Command("docker", "run", "--rm", "--entrypoint", "cat", image.ID, filepath.Join("/workspace", "vendor", "composer", path)).CombinedOutput()
+		Expect(err).NotTo(HaveOccurred(), string(output))
+
+		return string(output)
+	}
+
+	for _, server := range matrixServers() {
+		context(fmt.Sprintf("building a PHP app with an optimized Composer autoloader using the %s server", server.name), func() {
+			var fixture *harness.Fixture
+
+			it.Before(func() {
+				var err error
+				fixture, err = harness.NewFixture(phpBuildpack, filepath.Join("testdata", "simple_composer_app"))
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			it.After(func() {
+				Expect(fixture.Cleanup()).To(Succeed())
+			})
+
+			build := func(options string) occam.Image {
+				image, logs, err := fixture.Build().
+					WithEnv(server.buildEnv(map[string]string{"BP_COMPOSER_INSTALL_OPTIONS": options})).
+					Execute()
+				Expect(err).NotTo(HaveOccurred(), logs.String())
+
+				Expect(image).To(harness.HaveParticipated(server.order, server.optional(
+					"paketo-buildpacks/composer",
+					"paketo-buildpacks/composer-install",
+				)...))
+				Expect(logs).To(ContainLines(ContainSubstring(options)))
+
+				return image
+			}
+
+			it("generates a full classmap with --optimize-autoloader", func() {
+				image := build("--optimize-autoloader")
+
+				Expect(readVendorFile(image, "autoload_classmap.php")).To(ContainSubstring(`'Monolog\\Logger' =>`))
+				Expect(readVendorFile(image, "autoload_real.php")).NotTo(ContainSubstring("setClassMapAuthoritative(true)"))
+			})
+
+			it("makes the classmap authoritative with --classmap-authoritative", func() {
+				image := build("--classmap-authoritative")
+
+				Expect(readVendorFile(image, "autoload_classmap.php")).To(ContainSubstring(`'Monolog\\Logger' =>`))
+				Expect(readVendorFile(image, "autoload_real.php")).To(ContainSubstring("setClassMapAuthoritative(true)"))
+			})
+
+			it("caches class lookups in APCu with --apcu-autoloader", func() {
+				image := build("--apcu-autoloader")
+
+				Expect(readVendorFile(image, "autoload_real.php")).To(ContainSubstring("setApcuPrefix("))
+			})
+		})
+	}
+}
@@ -1,12 +1,20 @@
 package integration_test
 
 import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
-	"github.com/paketo-buildpacks/occam"
+	"github.com/paketo-buildpacks/php/integration/internal/harness"
 	"github.com/sclevine/spec"
 
 	. "github.com/onsi/gomega"
@@ -17,69 +25,465 @@ func testComposer(t *testing.T, context spec.G, it spec.S) {
 	var (
 		Expect     = NewWithT(t).Expect
 		Eventually = NewWithT(t).Eventually
-
-		pack   occam.Pack
-		docker occam.Docker
 	)
 
-	it.Before(func() {
-		pack = occam.NewPack().WithVerbose()
-		docker = occam.NewDocker()
+	context("building a PHP app that contains vendored Composer packages", func() {
+		var fixture *harness.Fixture
+
+		it.Before(func() {
+			var err error
+			fixture, err = harness.NewFixture(phpBuildpack, filepath.Join("testdata", "vendored_composer_app"))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it.After(func() {
+			Expect(fixture.Cleanup()).To(Succeed())
+		})
+
+		it("creates a working OCI image", func() {
+			image, logs, err := fixture.Build().
+				WithEnv(map[string]string{
+					"BP_PHP_SERVER": "httpd",
+				}).
+				Execute()
+			Expect(err).NotTo(HaveOccurred(), logs.String())
+
+			container, err := fixture.Run().Execute(image)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(container).Should(Serve(ContainSubstring("This is a PHP app.")).OnPort(8080))
+
+			Expect(image).To(harness.HaveParticipated(httpdOrder,
+				"paketo-buildpacks/ca-certificates",
+				"paketo-buildpacks/composer",
+				"paketo-buildpacks/composer-install",
+			))
+		})
 	})
 
-	context("building a PHP app that contains vendored Composer packages", func() {
-		var (
-			image     occam.Image
-			container occam.Container
+	context("building a PHP app that declares Composer scripts", func() {
+		var fixture *harness.Fixture
 
-			name   string
-			source string
-		)
+		it.Before(func() {
+			var err error
+			fixture, err = harness.NewFixture(phpBuildpack, filepath.Join("testdata", "composer_scripts_app"))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it.After(func() {
+			Expect(fixture.Cleanup()).To(Succeed())
+		})
+
+		it("runs the scripts during composer install", func() {
+			image, logs, err := fixture.Build().
+				WithEnv(map[string]string{
+					"BP_PHP_SERVER": "httpd",
+				}).
+				Execute()
+			Expect(err).NotTo(HaveOccurred(), logs.String())
+
+			container, err := fixture.Run().Execute(image)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(container).Should(Serve(And(
+				ContainSubstring("post-install-cmd: ran."),
+				ContainSubstring("post-autoload-dump: ran."),
+			)).OnPort(8080).WithEndpoint("/index.php"))
+
+			Expect(image).To(harness.HaveParticipated(httpdOrder,
+				"paketo-buildpacks/ca-certificates",
+				"paketo-buildpacks/composer",
+				"paketo-buildpacks/composer-install",
+			))
+		})
+
+		context("when BP_COMPOSER_INSTALL_OPTIONS disables scripts", func() {
+			it("skips the scripts during composer install", func() {
+				image, logs, err := fixture.Build().
+					WithEnv(map[string]string{
+						"BP_PHP_SERVER":               "httpd",
+						"BP_COMPOSER_INSTALL_OPTIONS": "--no-scripts",
+					}).
+					Execute()
+				Expect(err).NotTo(HaveOccurred(), logs.String())
+
+				container, err := fixture.Run().Execute(image)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(container).Should(Serve(And(
+					ContainSubstring("post-install-cmd: skipped."),
+					ContainSubstring("post-autoload-dump: skipped."),
+				)).OnPort(8080).WithEndpoint("/index.php"))
+
+				Expect(logs).To(ContainLines(ContainSubstring("--no-scripts")))
+			})
+		})
+	})
+
+	// This only covers selecting the Composer version. The composite has no
+	// build logic of its own, so it does not check the plugin-api-version of
+	// composer.lock against the selected Composer.
+	context("building a PHP app that selects its Composer version with BP_COMPOSER_VERSION", func() {
+		var fixture *harness.Fixture
 
 		it.Before(func() {
 			var err error
-			name, err = occam.RandomName()
+			fixture, err = harness.NewFixture(phpBuildpack, filepath.Join("testdata", "simple_composer_app"))
 			Expect(err).NotTo(HaveOccurred())
-			source, err = occam.Source(filepath.Join("testdata", "vendored_composer_app"))
+		})
+
+		it.After(func() {
+			Expect(fixture.Cleanup()).To(Succeed())
+		})
+
+		context("when BP_COMPOSER_VERSION selects Composer 2", func() {
+			it("installs the selected Composer version and the locked packages", func() {
+				image, logs, err := fixture.Build().
+					WithEnv(map[string]string{
+						"BP_PHP_SERVER":       "nginx",
+						"BP_COMPOSER_VERSION": "2.*",
+					}).
+					Execute()
+				Expect(err).NotTo(HaveOccurred(), logs.String())
+
+				Expect(logs).To(ContainLines(ContainSubstring(`BP_COMPOSER_VERSION -> "2.*"`)))
+				Expect(logs).To(ContainLines(MatchRegexp(`Selected Composer version \(using BP_COMPOSER_VERSION\): 2\.\d+\.\d+`)))
+
+				container, err := fixture.Run().Execute(image)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(container).Should(Serve(ContainSubstring("SUCCESS: date loads.")).OnPort(8080).WithEndpoint("/index.php?date"))
+			})
+		})
+
+		context("when BP_COMPOSER_VERSION matches no Composer version the composer buildpack provides", func() {
+			it("fails dependency resolution and names the constraint", func() {
+				_, logs, err := fixture.Build().
+					WithEnv(map[string]string{
+						"BP_PHP_SERVER":       "nginx",
+						"BP_COMPOSER_VERSION": "1.*",
+					}).
+					Execute()
+				Expect(err).To(HaveOccurred(), logs.String())
+
+				Expect(logs).To(ContainLines(ContainSubstring(`failed to satisfy "composer" dependency version constraint "1.*"`)))
+			})
+		})
+	})
+
+	context("building a PHP app that constrains the PHP version in composer.json", func() {
+		var fixture *harness.Fixture
+
+		it.Before(func() {
+			var err error
+			fixture, err = harness.NewFixture(phpBuildpack, filepath.Join("testdata", "php_constraint_apps"))
 			Expect(err).NotTo(HaveOccurred())
 		})
 
 		it.After(func() {
-			Expect(docker.Container.Remove.Execute(container.ID)).To(Succeed())
-			Expect(docker.Image.Remove.Execute(image.ID)).To(Succeed())
-			Expect(docker.Volume.Remove.Execute(occam.CacheVolumeNames(name))).To(Succeed())
-			Expect(os.RemoveAll(source)).To(Succeed())
+			Expect(fixture.Cleanup()).To(Succeed())
 		})
 
-		it("creates a working OCI image", func() {
+		context("when the constraint can be satisfied", func() {
+			it("installs a PHP version matching require.php", func() {
+				image, logs, err := fixture.Build().
+					WithEnv(map[string]string{
+						"BP_PHP_SERVER": "nginx",
+					}).
+					WithPath("supported_app").
+					Execute()
+				Expect(err).NotTo(HaveOccurred(), logs.String())
+
+				Expect(logs).To(ContainLines(ContainSubstring(`composer.json -> "^8.2"`)))
+				Expect(logs).To(ContainLines(MatchRegexp(`Selected PHP version \(using composer.json\): 8\.([2-9]|\d{2,})\.\d+`)))
+
+				container, err := fixture.Run().Execute(image)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(container).Should(Serve(MatchRegexp(`PHP 8\.([2-9]|\d{2,})\.\d+`)).OnPort(8080).WithEndpoint("/index.php"))
+			})
+		})
+
+		context("when the constraint cannot be satisfied", func() {
+			it("fails the build and names the conflicting constraint", func() {
+				_, logs, err := fixture.Build().
+					WithEnv(map[string]string{
+						"BP_PHP_SERVER": "nginx",
+					}).
+					WithPath("unsatisfiable_app").
+					Execute()
+				Expect(err).To(HaveOccurred(), logs.String())
+
+				Expect(logs).To(ContainLines(ContainSubstring(`composer.json -> ">=99.0"`)))
+				Expect(logs).To(ContainLines(ContainSubstring(`failed to satisfy "php" dependency version constraint ">=99.0"`)))
+			})
+		})
+	})
+
+	context("building one service out of a multi-service repository", func() {
+		var fixture *harness.Fixture
+
+		it.Before(func() {
 			var err error
-			var logs fmt.Stringer
-			image, logs, err = pack.WithNoColor().Build.
-				WithBuildpacks(phpBuildpack).
+			fixture, err = harness.NewFixture(phpBuildpack, filepath.Join("testdata", "multi_service_app"))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it.After(func() {
+			Expect(fixture.Cleanup()).To(Succeed())
+		})
+
+		it("installs and serves only the selected service", func() {
+			image, logs, err := fixture.Build().
+				WithEnv(map[string]string{
+					"BP_PHP_SERVER":  "nginx",
+					"BP_PHP_WEB_DIR": "services/api/htdocs",
+					"COMPOSER":       "services/api/composer.json",
+				}).
+				Execute()
+			Expect(err).NotTo(HaveOccurred(), logs.String())
+
+			Expect(image).To(harness.HaveParticipated(nginxOrder,
+				"paketo-buildpacks/ca-certificates",
+				"paketo-buildpacks/composer",
+				"paketo-buildpacks/composer-install",
+			))
+
+			container, err := fixture.Run().Execute(image)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(container).Should(Serve(ContainSubstring("This is the api service.")).OnPort(8080).WithEndpoint("/index.php"))
+
+			// The worker lies outside of the web directory and its dependencies,
+			// here psr/log, are never installed
+			response, err := http.Get(fmt.Sprintf("http://localhost:%s/services/worker/htdocs/index.php", container.HostPort("8080")))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.Body.Close()).To(Succeed())
+			Expect(response.StatusCode).To(Equal(http.StatusNotFound))
+
+			Expect(logs.String()).NotTo(ContainSubstring("psr/log"))
+
+			output, err := exec.Command("docker", "run", "--rm", "--entrypoint", "find", image.ID, "/workspace", "/layers", "-path", "*/psr/log").CombinedOutput()
+			Expect(err).NotTo(HaveOccurred(), string(output))
+			Expect(strings.TrimSpace(string(output))).To(BeEmpty())
+		})
+	})
+
+	// Mirroring path repositories that lie outside of the app, such as
+	// ../packages/*, is not supported: only the app directory is sent to the
+	// build. This covers in-app path repositories that the app itself mirrors
+	// with Composer's symlink option.
+	context("building a PHP app that uses in-app path and artifact Composer repositories", func() {
+		var fixture *harness.Fixture
+
+		it.Before(func() {
+			var err error
+			fixture, err = harness.NewFixture(phpBuildpack, filepath.Join("testdata", "composer_local_repositories_app"))
+			Expect(err).NotTo(HaveOccurred())
+
+			archive := composerPackageArchive(t, map[string]string{
+				"composer.json": `{"name": "paketo-buildpacks/farewell", "version": "1.0.0", "autoload": {"psr-4": {"Farewell\\": "src/"}}}`,
+				"src/Farewell.php": `<?php
+namespace Farewell;
+
+class Farewell
+{
+    public static function wave()
+    {
+        return "Goodbye from an artifact repository";
+    }
+}
+`,
+			})
+			Expect(os.MkdirAll(filepath.Join(fixture.Source, "artifacts"), os.ModePerm)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(fixture.Source, "artifacts", "farewell-1.0.0.zip"), archive, 0644)).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(fixture.Cleanup()).To(Succeed())
+		})
+
+		it("installs the packages the app mirrors with symlink: false into the vendor directory", func() {
+			image, logs, err := fixture.Build().
 				WithEnv(map[string]string{
 					"BP_PHP_SERVER": "httpd",
 				}).
-				WithPullPolicy("never").
-				Execute(name, source)
+				Execute()
 			Expect(err).NotTo(HaveOccurred(), logs.String())
 
-			container, err = docker.Container.Run.
-				WithEnv(map[string]string{"PORT": "8080"}).
-				WithPublish("8080").
-				WithPublishAll().
-				Execute(image.ID)
+			Expect(image).To(harness.HaveParticipated(httpdOrder,
+				"paketo-buildpacks/ca-certificates",
+				"paketo-buildpacks/composer",
+				"paketo-buildpacks/composer-install",
+			))
+
+			container, err := fixture.Run().Execute(image)
 			Expect(err).NotTo(HaveOccurred())
 
-			Eventually(container).Should(Serve(ContainSubstring("This is a PHP app.")).OnPort(8080))
+			Eventually(container).Should(Serve(And(
+				ContainSubstring("Hello from a path repository"),
+				ContainSubstring("Goodbye from an artifact repository"),
+				ContainSubstring("greeter is mirrored"),
+			)).OnPort(8080).WithEndpoint("/index.php"))
+		})
+	})
+
+	context("building a PHP app that depends on a private Composer repository", func() {
+		var (
+			fixture    *harness.Fixture
+			repository *httptest.Server
+			host       string
+		)
 
-			Expect(logs).To(ContainLines(ContainSubstring("Paketo Buildpack for CA Certificates")))
-			Expect(logs).To(ContainLines(ContainSubstring("Paketo Buildpack for PHP Distribution")))
-			Expect(logs).To(ContainLines(ContainSubstring("Paketo Buildpack for Composer")))
-			Expect(logs).To(ContainLines(ContainSubstring("Paketo Buildpack for Composer Install")))
-			Expect(logs).To(ContainLines(ContainSubstring("Paketo Buildpack for Apache HTTP Server")))
-			Expect(logs).To(ContainLines(ContainSubstring("Paketo Buildpack for PHP FPM")))
-			Expect(logs).To(ContainLines(ContainSubstring("Paketo Buildpack for PHP HTTPD")))
-			Expect(logs).To(ContainLines(ContainSubstring("Paketo Buildpack for PHP Start")))
+		it.Before(func() {
+			var err error
+			fixture, err = harness.NewFixture(phpBuildpack, filepath.Join("testdata", "composer_auth_app"))
+			Expect(err).NotTo(HaveOccurred())
+
+			// The build container reaches the repository through the gateway of
+			// the default docker bridge network
+			output, err := exec.Command("docker", "network", "inspect", "bridge", "--format", "{{(index .IPAM.Config 0).Gateway}}").CombinedOutput()
+			Expect(err).NotTo(HaveOccurred(), string(output))
+			gateway := strings.TrimSpace(string(output))
+
+			// Listen on the gateway only, so the repository and its credentials are
+			// not exposed on any other host interface during the build
+			listener, err := net.Listen("tcp", net.JoinHostPort(gateway, "0"))
+			Expect(err).NotTo(HaveOccurred())
+
+			host = listener.Addr().String()
+
+			repository = httptest.NewUnstartedServer(privateComposerRepository(t, host, "composer-user", "composer-secret"))
+			repository.Listener = listener
+			repository.Start()
+
+			content, err := os.ReadFile(filepath.Join(fixture.Source, "composer.json"))
+			Expect(err).NotTo(HaveOccurred())
+			content = bytes.ReplaceAll(content, []byte("private-repository.example"), []byte(host))
+			Expect(os.WriteFile(filepath.Join(fixture.Source, "composer.json"), content, 0644)).To(Succeed())
+		})
+
+		it.After(func() {
+			repository.Close()
+
+			Expect(fixture.Cleanup()).To(Succeed())
 		})
+
+		// The composite has no composer-auth service binding, so this passes the
+		// credentials through COMPOSER_AUTH as a workaround
+		it("authenticates with the credentials given in COMPOSER_AUTH, not a composer-auth binding", func() {
+			// Composer looks up the credentials by host and port whenever the port is
+			// not the default one of the scheme
+			auth, err := json.Marshal(map[string]interface{}{
+				"http-basic": map[string]interface{}{
+					host: map[string]string{
+						"username": "composer-user",
+						"password": "composer-secret",
+					},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			image, logs, err := fixture.Build().
+				WithEnv(map[string]string{
+					"BP_PHP_SERVER": "nginx",
+					"COMPOSER_AUTH": string(auth),
+				}).
+				Execute()
+			Expect(err).NotTo(HaveOccurred(), logs.String())
+
+			Expect(image).To(harness.HaveParticipated(nginxOrder,
+				"paketo-buildpacks/ca-certificates",
+				"paketo-buildpacks/composer",
+				"paketo-buildpacks/composer-install",
+			))
+			Expect(logs.String()).NotTo(ContainSubstring("composer-secret"))
+
+			container, err := fixture.Run().Execute(image)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(container).Should(Serve(ContainSubstring("Hello from a private repository")).OnPort(8080).WithEndpoint("/index.php"))
+		})
+	})
+}
+
+// privateComposerRepository serves a Composer repository containing a single
+// package, paketo-buildpacks/greeter, behind HTTP basic auth.
+func privateComposerRepository(t *testing.T, host, username, password string) http.Handler {
+	Expect := NewWithT(t).Expect
+
+	archive := composerPackageArchive(t, map[string]string{
+		"greeter/composer.json": `{"name": "paketo-buildpacks/greeter", "autoload": {"psr-4": {"Greeter\\": "src/"}}}`,
+		"greeter/src/Greeter.php": `<?php
+namespace Greeter;
+
+class Greeter
+{
+    public static function greet()
+    {
+        return "Hello from a private repository";
+    }
+}
+`,
+	})
+
+	packages, err := json.Marshal(map[string]interface{}{
+		"packages": map[string]interface{}{
+			"paketo-buildpacks/greeter": map[string]interface{}{
+				"1.0.0": map[string]interface{}{
+					"name":    "paketo-buildpacks/greeter",
+					"version": "1.0.0",
+					"type":    "library",
+					"dist": map[string]string{
+						"type": "zip",
+						"url":  fmt.Sprintf("http://%s/dist/greeter-1.0.0.zip", host),
+					},
+					"autoload": map[string]interface{}{
+						"psr-4": map[string]string{"Greeter\\": "src/"},
+					},
+				},
+			},
+		},
 	})
+	Expect(err).NotTo(HaveOccurred())
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		user, pass, ok := req.BasicAuth()
+		if !ok || user != username || pass != password {
+			w.Header().Set("WWW-Authenticate", `Basic realm="composer"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch req.URL.Path {
+		case "/packages.json":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write(packages)
+		case "/dist/greeter-1.0.0.zip":
+			w.Header().Set("Content-Type", "application/zip")
+			_, _ = w.Write(archive)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+}
+
+// composerPackageArchive returns a zip archive of the given files in the
+// layout Composer expects for dist and artifact packages.
+func composerPackageArchive(t *testing.T, files map[string]string) []byte {
+	Expect := NewWithT(t).Expect
+
+	buffer := bytes.NewBuffer(nil)
+	archive := zip.NewWriter(buffer)
+
+	for path, content := range files {
+		file, err := archive.Create(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = file.Write([]byte(content))
+		Expect(err).NotTo(HaveOccurred())
+	}
+	Expect(archive.Close()).To(Succeed())
+
+	return buffer.Bytes()
 }
@@ -2,12 +2,15 @@ package integration_test
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/paketo-buildpacks/occam"
+	"github.com/paketo-buildpacks/php/integration/internal/harness"
 	"github.com/sclevine/spec"
 	"github.com/sclevine/spec/report"
 
@@ -16,10 +19,15 @@ import (
 )
 
 var (
-	phpBuildpack   string
-	builder        occam.Builder
-	memcachedImage string
-	redisImage     string
+	phpBuildpack      string
+	budgets           harness.Budgets
+	budgetReport      *harness.BudgetReport
+	builder           occam.Builder
+	builtinOrder      harness.Order
+	httpdOrder        harness.Order
+	nginxOrder        harness.Order
+	postgresImage     string
+	sessionStoreImage string
 )
 
 func TestIntegration(t *testing.T) {
@@ -37,22 +45,52 @@ func TestIntegration(t *testing.T) {
 
 	SetDefaultEventuallyTimeout(10 * time.Second)
 
+	orders, err := harness.ParseOrders("../buildpack.toml")
+	Expect(err).NotTo(HaveOccurred())
+
+	builtinOrder, err = orders.Containing("paketo-buildpacks/php-builtin-server")
+	Expect(err).NotTo(HaveOccurred())
+
+	httpdOrder, err = orders.Containing("paketo-buildpacks/php-httpd")
+	Expect(err).NotTo(HaveOccurred())
+
+	nginxOrder, err = orders.Containing("paketo-buildpacks/php-nginx")
+	Expect(err).NotTo(HaveOccurred())
+
 	builder, err = pack.Builder.Inspect.Execute()
 	Expect(err).NotTo(HaveOccurred())
 
-	// pull and re-tag memcached image with builder-specific naming
+	budgets, err = harness.ParseBudgets("budgets.json")
+	Expect(err).NotTo(HaveOccurred())
+
+	// BUDGET_MARGIN overrides the margin from the budget file, e.g. on slower CI runners
+	if margin, ok := os.LookupEnv("BUDGET_MARGIN"); ok {
+		budgets.Margin, err = strconv.ParseFloat(margin, 64)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	budgetReport = harness.NewBudgetReport(builder.BuilderName, budgets.Margin)
+
+	// READ_ONLY_WRITABLE_PATH overrides the writable path of the read-only
+	// filesystem tests to match the platform the images are deployed to
+	if path, ok := os.LookupEnv("READ_ONLY_WRITABLE_PATH"); ok {
+		readOnlyWritablePath = path
+	}
+
+	// build the session store that stands in for both memcached and redis with builder-specific naming
 	// this will prevent flakes in which we try to reference/remove the same image in parallel bionic/jammy builder tests
-	memcachedImage = fmt.Sprintf("memcached-%s:latest", builder.LocalInfo.Stack.ID)
-	Expect(docker.Pull.Execute("memcached:latest")).To(Succeed())
-	Expect(docker.Image.Tag.Execute("memcached:latest", memcachedImage)).To(Succeed())
-	Expect(docker.Image.Remove.WithForce().Execute("memcached:latest")).To(Succeed())
+	sessionStoreImage = fmt.Sprintf("session-store-%s:latest", builder.LocalInfo.Stack.ID)
+	Expect(harness.BuildGoImage(sessionStoreImage, "./internal/sessionstore/cmd/session-store", "6379", "11211")).To(Succeed())
 
-	// pull and re-tag redis image with builder-specific naming
+	// pull and re-tag postgres image with builder-specific naming
 	// this will prevent flakes in which we try to reference/remove the same image in parallel bionic/jammy builder tests
-	redisImage = fmt.Sprintf("redis-%s:latest", builder.LocalInfo.Stack.ID)
-	Expect(docker.Pull.Execute("redis:latest")).To(Succeed())
-	Expect(docker.Image.Tag.Execute("redis:latest", redisImage)).To(Succeed())
-	Expect(docker.Image.Remove.WithForce().Execute("redis:latest")).To(Succeed())
+	// Unlike the session store, this is pulled from Docker Hub, so the suite
+	// needs network access and is subject to its rate limits. The major
+	// version is pinned so the migrate tests do not change under us.
+	postgresImage = fmt.Sprintf("postgres-%s:latest", builder.LocalInfo.Stack.ID)
+	Expect(docker.Pull.Execute("postgres:16")).To(Succeed())
+	Expect(docker.Image.Tag.Execute("postgres:16", postgresImage)).To(Succeed())
+	Expect(docker.Image.Remove.WithForce().Execute("postgres:16")).To(Succeed())
 
 	suite := spec.New("Integration", spec.Parallel(), spec.Report(report.Terminal{}))
 
@@ -62,17 +100,27 @@ func TestIntegration(t *testing.T) {
 		suite("StackUpgrades", testStackUpgrades)
 	}
 
-	suite("Builtin Server", testPhpBuiltinServer)
 	suite("Composer", testComposer)
-	suite("HTTPD", testPhpHttpd)
-	suite("Memcached Session Handler", testMemcachedSessionHandler)
-	suite("Nginx", testPhpNginx)
-	suite("Redis Session Handler", testRedisSessionHandler)
+	suite("Composer Autoload", testComposerAutoload)
+	suite("Migrate Process", testMigrateProcess)
+	suite("Project TOML", testProjectToml)
+	suite("Rebuild", testRebuild)
 	suite("Reproducible Builds", testReproducibleBuilds)
+	suite("SBOM", testSBOM)
+	suite("Server Matrix", testServerMatrix)
 	suite.Run(t)
 
-	// Clean up memcached image
-	Expect(docker.Image.Remove.WithForce().Execute(memcachedImage)).To(Succeed())
-	// Clean up redis image
-	Expect(docker.Image.Remove.WithForce().Execute(redisImage)).To(Succeed())
+	// Measure the budgets only once every other suite has finished, one build at
+	// a time, so that concurrent builds do not inflate the build times
+	budgetSuite := spec.New("Integration Budgets", spec.Sequential(), spec.Report(report.Terminal{}))
+	budgetSuite("Budgets", testBudgets)
+	budgetSuite.Run(t)
+
+	// Write the budget report, one per stack so that runs against several builders do not overwrite each other
+	Expect(budgetReport.Write(filepath.Join("..", "build", fmt.Sprintf("budget-report-%s.json", builder.LocalInfo.Stack.ID)))).To(Succeed())
+
+	// Clean up postgres image
+	Expect(docker.Image.Remove.WithForce().Execute(postgresImage)).To(Succeed())
+	// Clean up session store image
+	Expect(docker.Image.Remove.WithForce().Execute(sessionStoreImage)).To(Succeed())
 }
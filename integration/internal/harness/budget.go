@@ -0,0 +1,140 @@
+package harness
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Budgets are the image size and build time limits of each fixture when it is
+// built with each server, as recorded in a budget file.
+type Budgets struct {
+	// Margin is the fraction by which a measurement may exceed its budget
+	// before it counts as a regression.
+	Margin  float64  `json:"margin"`
+	Budgets []Budget `json:"budgets"`
+}
+
+// Budget limits the image size and build time of one fixture built with one
+// server. A budget with a stack only applies to builds on that stack, so that
+// stacks whose images differ can each have their own limits. A limit of zero
+// has not been measured yet and counts as exceeded.
+type Budget struct {
+	Server           string            `json:"server"`
+	Fixture          string            `json:"fixture"`
+	Stack            string            `json:"stack,omitempty"`
+	Env              map[string]string `json:"env,omitempty"`
+	ImageSizeMB      float64           `json:"image_size_mb,omitempty"`
+	BuildTimeSeconds float64           `json:"build_time_seconds,omitempty"`
+}
+
+// Measurement is the image size and build time observed for a budget.
+type Measurement struct {
+	ImageSizeMB      float64 `json:"image_size_mb"`
+	BuildTimeSeconds float64 `json:"build_time_seconds"`
+}
+
+// ParseBudgets reads the budget file at path.
+func ParseBudgets(path string) (Budgets, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Budgets{}, fmt.Errorf("failed to open budget file: %w", err)
+	}
+	defer file.Close()
+
+	var budgets Budgets
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&budgets); err != nil {
+		return Budgets{}, fmt.Errorf("failed to decode budget file: %w", err)
+	}
+
+	if budgets.Margin < 0 {
+		return Budgets{}, fmt.Errorf("budget margin must not be negative, got %v", budgets.Margin)
+	}
+
+	return budgets, nil
+}
+
+// Exceeded describes every limit of the budget that the measurement exceeds
+// by more than the given margin, and every limit that is not set.
+func (b Budget) Exceeded(measurement Measurement, margin float64) []string {
+	var exceeded []string
+	switch limit := b.ImageSizeMB * (1 + margin); {
+	case b.ImageSizeMB <= 0:
+		exceeded = append(exceeded, fmt.Sprintf("image size budget is not set, measured %.1f MB", measurement.ImageSizeMB))
+	case measurement.ImageSizeMB > limit:
+		exceeded = append(exceeded, fmt.Sprintf("image size %.1f MB exceeds budget of %.1f MB by more than %.0f%%", measurement.ImageSizeMB, b.ImageSizeMB, margin*100))
+	}
+
+	switch limit := b.BuildTimeSeconds * (1 + margin); {
+	case b.BuildTimeSeconds <= 0:
+		exceeded = append(exceeded, fmt.Sprintf("build time budget is not set, measured %.1fs", measurement.BuildTimeSeconds))
+	case measurement.BuildTimeSeconds > limit:
+		exceeded = append(exceeded, fmt.Sprintf("build time %.1fs exceeds budget of %.1fs by more than %.0f%%", measurement.BuildTimeSeconds, b.BuildTimeSeconds, margin*100))
+	}
+
+	return exceeded
+}
+
+// AppliesTo reports whether the budget applies to builds on the given stack.
+func (b Budget) AppliesTo(stack string) bool {
+	return b.Stack == "" || b.Stack == stack
+}
+
+// BudgetReport collects the measurements of a test run so they can be
+// written out as JSON once every suite has finished. It is safe for
+// concurrent use.
+type BudgetReport struct {
+	mutex sync.Mutex
+
+	Builder string         `json:"builder"`
+	Margin  float64        `json:"margin"`
+	Results []BudgetResult `json:"results"`
+}
+
+// BudgetResult is the outcome of measuring a single budget.
+type BudgetResult struct {
+	Budget      Budget      `json:"budget"`
+	Measurement Measurement `json:"measurement"`
+	Exceeded    []string    `json:"exceeded"`
+}
+
+// NewBudgetReport returns an empty report for the given builder and margin.
+func NewBudgetReport(builder string, margin float64) *BudgetReport {
+	return &BudgetReport{
+		Builder: builder,
+		Margin:  margin,
+		Results: []BudgetResult{},
+	}
+}
+
+// Record checks the measurement against the budget using the report's
+// margin, adds the result to the report and returns it.
+func (r *BudgetReport) Record(budget Budget, measurement Measurement) BudgetResult {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	result := BudgetResult{
+		Budget:      budget,
+		Measurement: measurement,
+		Exceeded:    budget.Exceeded(measurement, r.Margin),
+	}
+	r.Results = append(r.Results, result)
+
+	return result
+}
+
+// Write stores the report as indented JSON at path.
+func (r *BudgetReport) Write(path string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	content, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(content, '\n'), 0644)
+}
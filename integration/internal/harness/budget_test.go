@@ -0,0 +1,151 @@
+package harness_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/php/integration/internal/harness"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testBudgets(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		dir string
+	)
+
+	it.Before(func() {
+		dir = t.TempDir()
+	})
+
+	context("ParseBudgets", func() {
+		it("reads the margin and every budget", func() {
+			path := filepath.Join(dir, "budgets.json")
+			Expect(os.WriteFile(path, []byte(`{
+  "margin": 0.1,
+  "budgets": [
+    {
+      "server": "HTTPD",
+      "fixture": "simple_composer_app",
+      "stack": "io.buildpacks.stacks.jammy",
+      "env": {"BP_LOG_LEVEL": "DEBUG"},
+      "image_size_mb": 1200,
+      "build_time_seconds": 300
+    }
+  ]
+}`), 0644)).To(Succeed())
+
+			budgets, err := harness.ParseBudgets(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(budgets).To(Equal(harness.Budgets{
+				Margin: 0.1,
+				Budgets: []harness.Budget{
+					{
+						Server:           "HTTPD",
+						Fixture:          "simple_composer_app",
+						Stack:            "io.buildpacks.stacks.jammy",
+						Env:              map[string]string{"BP_LOG_LEVEL": "DEBUG"},
+						ImageSizeMB:      1200,
+						BuildTimeSeconds: 300,
+					},
+				},
+			}))
+		})
+
+		context("failure cases", func() {
+			it("returns an error when the file does not exist", func() {
+				_, err := harness.ParseBudgets(filepath.Join(dir, "missing.json"))
+				Expect(err).To(MatchError(ContainSubstring("failed to open budget file")))
+			})
+
+			it("returns an error when the file has unknown fields", func() {
+				path := filepath.Join(dir, "budgets.json")
+				Expect(os.WriteFile(path, []byte(`{"margin": 0.1, "budgets": [{"image_size": 1200}]}`), 0644)).To(Succeed())
+
+				_, err := harness.ParseBudgets(path)
+				Expect(err).To(MatchError(ContainSubstring("failed to decode budget file")))
+			})
+
+			it("returns an error when the margin is negative", func() {
+				path := filepath.Join(dir, "budgets.json")
+				Expect(os.WriteFile(path, []byte(`{"margin": -0.1, "budgets": []}`), 0644)).To(Succeed())
+
+				_, err := harness.ParseBudgets(path)
+				Expect(err).To(MatchError("budget margin must not be negative, got -0.1"))
+			})
+		})
+	})
+
+	context("Exceeded", func() {
+		var budget harness.Budget
+
+		it.Before(func() {
+			budget = harness.Budget{ImageSizeMB: 1000, BuildTimeSeconds: 100}
+		})
+
+		it("allows measurements within the margin", func() {
+			Expect(budget.Exceeded(harness.Measurement{ImageSizeMB: 1100, BuildTimeSeconds: 110}, 0.1)).To(BeEmpty())
+		})
+
+		it("reports the limits that are not set", func() {
+			budget = harness.Budget{BuildTimeSeconds: 100}
+
+			Expect(budget.Exceeded(harness.Measurement{ImageSizeMB: 5000, BuildTimeSeconds: 110}, 0.1)).To(Equal([]string{
+				"image size budget is not set, measured 5000.0 MB",
+			}))
+			Expect(harness.Budget{}.Exceeded(harness.Measurement{ImageSizeMB: 5000, BuildTimeSeconds: 110}, 0.1)).To(Equal([]string{
+				"image size budget is not set, measured 5000.0 MB",
+				"build time budget is not set, measured 110.0s",
+			}))
+		})
+
+		it("describes every limit exceeded beyond the margin", func() {
+			Expect(budget.Exceeded(harness.Measurement{ImageSizeMB: 1100.5, BuildTimeSeconds: 111}, 0.1)).To(Equal([]string{
+				"image size 1100.5 MB exceeds budget of 1000.0 MB by more than 10%",
+				"build time 111.0s exceeds budget of 100.0s by more than 10%",
+			}))
+		})
+	})
+
+	context("AppliesTo", func() {
+		it("applies budgets without a stack to every stack", func() {
+			Expect(harness.Budget{}.AppliesTo("io.buildpacks.stacks.jammy")).To(BeTrue())
+		})
+
+		it("applies budgets with a stack to that stack only", func() {
+			budget := harness.Budget{Stack: "io.buildpacks.stacks.jammy"}
+			Expect(budget.AppliesTo("io.buildpacks.stacks.jammy")).To(BeTrue())
+			Expect(budget.AppliesTo("io.buildpacks.stacks.noble")).To(BeFalse())
+		})
+	})
+
+	context("BudgetReport", func() {
+		it("records the results and writes them as JSON", func() {
+			report := harness.NewBudgetReport("some-builder", 0.1)
+
+			result := report.Record(harness.Budget{Server: "Nginx", Fixture: "simple_composer_app", ImageSizeMB: 1000, BuildTimeSeconds: 100}, harness.Measurement{ImageSizeMB: 1200, BuildTimeSeconds: 50})
+			Expect(result.Exceeded).To(HaveLen(1))
+
+			path := filepath.Join(dir, "report.json")
+			Expect(report.Write(path)).To(Succeed())
+
+			content, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+
+			var written struct {
+				Builder string                 `json:"builder"`
+				Margin  float64                `json:"margin"`
+				Results []harness.BudgetResult `json:"results"`
+			}
+			Expect(json.Unmarshal(content, &written)).To(Succeed())
+			Expect(written.Builder).To(Equal("some-builder"))
+			Expect(written.Margin).To(Equal(0.1))
+			Expect(written.Results).To(Equal([]harness.BudgetResult{result}))
+		})
+	})
+}
@@ -0,0 +1,316 @@
+package harness
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/paketo-buildpacks/occam"
+)
+
+// Fixture builds, runs and serves a copy of a single testdata app. It keeps
+// track of every image, container and cache volume it creates so that Reset
+// and Cleanup can remove them again.
+type Fixture struct {
+	Name   string
+	Source string
+
+	buildpack string
+	pack      occam.Pack
+	docker    occam.Docker
+
+	images     []string
+	containers []string
+	services   []string
+}
+
+// NewFixture copies the app at path into a temporary source directory and
+// picks a random image name for it. Builds use the given buildpack.
+func NewFixture(buildpack, path string) (*Fixture, error) {
+	name, err := occam.RandomName()
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := occam.Source(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Fixture{
+		Name:      name,
+		Source:    source,
+		buildpack: buildpack,
+		pack:      occam.NewPack().WithVerbose(),
+		docker:    occam.NewDocker(),
+	}, nil
+}
+
+// Build returns a pack build of the fixture source with the buildpack under
+// test and a "never" pull policy.
+func (f *Fixture) Build() FixtureBuild {
+	return FixtureBuild{
+		fixture: f,
+		build: f.pack.WithNoColor().Build.
+			WithBuildpacks(f.buildpack).
+			WithPullPolicy("never"),
+		path: f.Source,
+	}
+}
+
+// Run returns a container run that sets PORT to 8080 and publishes that
+// port, the way every app image in the suite is served.
+func (f *Fixture) Run() FixtureRun {
+	return FixtureRun{
+		fixture: f,
+		env:     map[string]string{"PORT": "8080"},
+		publish: true,
+	}
+}
+
+// RunService starts a backing service, such as a session store, from the
+// given image and publishes its port.
+func (f *Fixture) RunService(image, port string, env map[string]string) (occam.Container, error) {
+	container, err := f.docker.Container.Run.
+		WithEnv(env).
+		WithPublish(port).
+		Execute(image)
+	if err != nil {
+		return occam.Container{}, err
+	}
+	f.services = append(f.services, container.ID)
+
+	return container, nil
+}
+
+// Logs returns a function that reads the logs of the container, for use
+// with Eventually.
+func (f *Fixture) Logs(container occam.Container) func() (string, error) {
+	return func() (string, error) {
+		logs, err := f.docker.Container.Logs.Execute(container.ID)
+		if err != nil {
+			return "", err
+		}
+
+		return logs.String(), nil
+	}
+}
+
+// Reset removes every app container, image and cache volume the fixture has
+// created so far. It keeps the source and any running services so that the
+// app can be built again.
+func (f *Fixture) Reset() error {
+	var errs []error
+	for i := len(f.containers) - 1; i >= 0; i-- {
+		errs = append(errs, f.docker.Container.Remove.Execute(f.containers[i]))
+	}
+	f.containers = nil
+
+	removed := map[string]struct{}{}
+	for _, id := range f.images {
+		if _, ok := removed[id]; ok {
+			continue
+		}
+		removed[id] = struct{}{}
+
+		errs = append(errs, f.docker.Image.Remove.Execute(id))
+	}
+	f.images = nil
+
+	errs = append(errs, f.docker.Volume.Remove.Execute(occam.CacheVolumeNames(f.Name)))
+
+	return errors.Join(errs...)
+}
+
+// Cleanup resets the fixture, stops its services and removes its source
+// directory.
+func (f *Fixture) Cleanup() error {
+	errs := []error{f.Reset()}
+	for _, id := range f.services {
+		errs = append(errs, f.docker.Container.Remove.Execute(id))
+	}
+	f.services = nil
+
+	return errors.Join(append(errs, os.RemoveAll(f.Source))...)
+}
+
+// FixtureBuild is a pack build of a fixture. Images it produces are removed
+// by the fixture's Reset and Cleanup.
+type FixtureBuild struct {
+	fixture *Fixture
+	build   occam.PackBuild
+	path    string
+}
+
+// WithEnv sets build-time environment variables.
+func (b FixtureBuild) WithEnv(env map[string]string) FixtureBuild {
+	b.build = b.build.WithEnv(env)
+	return b
+}
+
+// WithVolumes mounts volumes into the build containers.
+func (b FixtureBuild) WithVolumes(volumes ...string) FixtureBuild {
+	b.build = b.build.WithVolumes(volumes...)
+	return b
+}
+
+// WithClearCache builds without reusing the cache volumes.
+func (b FixtureBuild) WithClearCache() FixtureBuild {
+	b.build = b.build.WithClearCache()
+	return b
+}
+
+// WithSBOMOutputDir makes pack write the SBOMs of the build to dir.
+func (b FixtureBuild) WithSBOMOutputDir(dir string) FixtureBuild {
+	b.build = b.build.WithSBOMOutputDir(dir)
+	return b
+}
+
+// WithPath builds a sub-directory of the fixture source instead of its root.
+func (b FixtureBuild) WithPath(elem ...string) FixtureBuild {
+	b.path = filepath.Join(append([]string{b.fixture.Source}, elem...)...)
+	return b
+}
+
+// Execute runs the build.
+func (b FixtureBuild) Execute() (occam.Image, fmt.Stringer, error) {
+	image, logs, err := b.build.Execute(b.fixture.Name, b.path)
+	if image.ID != "" {
+		b.fixture.images = append(b.fixture.images, image.ID)
+	}
+
+	return image, logs, err
+}
+
+// FixtureRun is a container run of a fixture image. Containers it starts are
+// removed by the fixture's Reset and Cleanup.
+type FixtureRun struct {
+	fixture    *Fixture
+	env        map[string]string
+	volumes    []string
+	entrypoint string
+	publish    bool
+
+	user     string
+	readOnly bool
+	tmpfs    []string
+}
+
+// WithEnv adds launch-time environment variables to PORT.
+func (r FixtureRun) WithEnv(env map[string]string) FixtureRun {
+	merged := maps.Clone(r.env)
+	maps.Copy(merged, env)
+	r.env = merged
+
+	return r
+}
+
+// WithVolumes mounts volumes into the container.
+func (r FixtureRun) WithVolumes(volumes ...string) FixtureRun {
+	r.volumes = append(slices.Clone(r.volumes), volumes...)
+	return r
+}
+
+// WithEntrypoint runs the given process type instead of the default one.
+// The container is not published since it is not expected to serve.
+func (r FixtureRun) WithEntrypoint(entrypoint string) FixtureRun {
+	r.entrypoint = entrypoint
+	r.publish = false
+	return r
+}
+
+// WithUser runs the container as the given user, in docker's user[:group]
+// form, instead of the user the image was built for.
+func (r FixtureRun) WithUser(user string) FixtureRun {
+	r.user = user
+	return r
+}
+
+// WithReadOnlyRoot mounts the root filesystem of the container read-only
+// and mounts a tmpfs at each of the given writable paths.
+func (r FixtureRun) WithReadOnlyRoot(writable ...string) FixtureRun {
+	r.readOnly = true
+	r.tmpfs = append(slices.Clone(r.tmpfs), writable...)
+	return r
+}
+
+// Execute starts the container.
+func (r FixtureRun) Execute(image occam.Image) (occam.Container, error) {
+	// occam can neither change the user nor mount a tmpfs, so those runs go
+	// through the docker CLI
+	if r.user != "" || r.readOnly {
+		return r.executeWithCLI(image)
+	}
+
+	run := r.fixture.docker.Container.Run.
+		WithEnv(r.env).
+		WithVolumes(r.volumes...)
+	if r.entrypoint != "" {
+		run = run.WithEntrypoint(r.entrypoint)
+	}
+	if r.publish {
+		run = run.WithPublish("8080").WithPublishAll()
+	}
+
+	container, err := run.Execute(image.ID)
+	if err != nil {
+		return occam.Container{}, err
+	}
+	r.fixture.containers = append(r.fixture.containers, container.ID)
+
+	return container, nil
+}
+
+func (r FixtureRun) executeWithCLI(image occam.Image) (occam.Container, error) {
+	args := []string{"container", "run", "--detach"}
+
+	for _, key := range slices.Sorted(maps.Keys(r.env)) {
+		args = append(args, "--env", fmt.Sprintf("%s=%s", key, r.env[key]))
+	}
+
+	for _, volume := range r.volumes {
+		args = append(args, "--volume", volume)
+	}
+
+	if r.entrypoint != "" {
+		args = append(args, "--entrypoint", r.entrypoint)
+	}
+
+	if r.publish {
+		args = append(args, "--publish", "8080", "--publish-all")
+	}
+
+	if r.user != "" {
+		args = append(args, "--user", r.user)
+	}
+
+	if r.readOnly {
+		args = append(args, "--read-only")
+	}
+
+	for _, path := range r.tmpfs {
+		args = append(args, "--tmpfs", path)
+	}
+
+	output, err := exec.Command("docker", append(args, image.ID)...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return occam.Container{}, fmt.Errorf("failed to run container: %w\n%s", err, exitErr.Stderr)
+		}
+
+		return occam.Container{}, fmt.Errorf("failed to run container: %w", err)
+	}
+
+	// Track the container before inspecting it, so that Cleanup removes it
+	// even when the inspection fails
+	id := strings.TrimSpace(string(output))
+	r.fixture.containers = append(r.fixture.containers, id)
+
+	return r.fixture.docker.Container.Inspect.Execute(id)
+}
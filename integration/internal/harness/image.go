@@ -0,0 +1,69 @@
+package harness
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/paketo-buildpacks/occam"
+)
+
+// BuildGoImage compiles the Go command package at pkg into a static Linux
+// binary and builds a scratch image around it, tagged with the given tag.
+// The binary is the entrypoint of the image and the given ports are exposed.
+// Images built this way let suites run backing services without pulling
+// anything from a registry.
+func BuildGoImage(tag, pkg string, ports ...string) error {
+	dir, err := os.MkdirTemp("", "go-image")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Base(pkg)
+
+	build := exec.Command("go", "build", "-trimpath", "-o", filepath.Join(dir, name), pkg)
+	build.Env = append(os.Environ(), "CGO_ENABLED=0", "GOOS=linux", fmt.Sprintf("GOARCH=%s", runtime.GOARCH))
+	if output, err := build.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to build %s: %w\n%s", pkg, err, output)
+	}
+
+	var dockerfile strings.Builder
+	dockerfile.WriteString("FROM scratch\n")
+	fmt.Fprintf(&dockerfile, "COPY %s /%s\n", name, name)
+	for _, port := range ports {
+		fmt.Fprintf(&dockerfile, "EXPOSE %s\n", port)
+	}
+	fmt.Fprintf(&dockerfile, "ENTRYPOINT [\"/%s\"]\n", name)
+
+	err = os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte(dockerfile.String()), 0644)
+	if err != nil {
+		return err
+	}
+
+	if output, err := exec.Command("docker", "build", "--tag", tag, dir).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to build image %s: %w\n%s", tag, err, output)
+	}
+
+	return nil
+}
+
+// ImageSize returns the size of the image in bytes as reported by docker
+// image inspect.
+func ImageSize(image occam.Image) (int64, error) {
+	output, err := exec.Command("docker", "image", "inspect", "--format", "{{.Size}}", image.ID).CombinedOutput()
+	if err != nil {
+		return 0, fmt.Errorf("failed to inspect image %s: %w\n%s", image.ID, err, output)
+	}
+
+	size, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse size of image %s: %w", image.ID, err)
+	}
+
+	return size, nil
+}
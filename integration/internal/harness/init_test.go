@@ -0,0 +1,18 @@
+package harness_test
+
+import (
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestUnitHarness(t *testing.T) {
+	suite := spec.New("harness", spec.Report(report.Terminal{}))
+	suite("Budgets", testBudgets)
+	suite("HaveBuildpack", testHaveBuildpack)
+	suite("HaveParticipated", testHaveParticipated)
+	suite("Orders", testOrders)
+	suite("SBOM", testSBOM)
+	suite.Run(t)
+}
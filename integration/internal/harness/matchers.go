@@ -0,0 +1,234 @@
+package harness
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/onsi/gomega/types"
+	"github.com/paketo-buildpacks/occam"
+)
+
+// HaveParticipated succeeds when the buildpack metadata of an occam.Image
+// lists every required buildpack of the order group, together with the given
+// optional buildpacks, and no other buildpack.
+func HaveParticipated(order Order, optional ...string) types.GomegaMatcher {
+	return &participationMatcher{
+		order:    order,
+		optional: optional,
+	}
+}
+
+type participationMatcher struct {
+	order    Order
+	optional []string
+
+	missing    []string
+	unexpected []string
+}
+
+func (m *participationMatcher) Match(actual interface{}) (bool, error) {
+	image, ok := actual.(occam.Image)
+	if !ok {
+		return false, fmt.Errorf("HaveParticipated expects an occam.Image, got %T", actual)
+	}
+
+	for _, id := range m.optional {
+		if _, ok := m.order.Buildpack(id); !ok {
+			return false, fmt.Errorf("buildpack %q is not part of the order group", id)
+		}
+	}
+
+	m.missing, m.unexpected = nil, nil
+
+	ids := imageBuildpackIDs(image)
+	for _, id := range ids {
+		if _, ok := m.order.Buildpack(id); !ok {
+			m.unexpected = append(m.unexpected, id)
+		}
+	}
+
+	for _, buildpack := range m.order.Group {
+		expected := !buildpack.Optional || slices.Contains(m.optional, buildpack.ID)
+		participated := slices.Contains(ids, buildpack.ID)
+
+		switch {
+		case expected && !participated:
+			m.missing = append(m.missing, buildpack.ID)
+		case !expected && participated:
+			m.unexpected = append(m.unexpected, buildpack.ID)
+		}
+	}
+
+	return len(m.missing) == 0 && len(m.unexpected) == 0, nil
+}
+
+func (m *participationMatcher) FailureMessage(actual interface{}) string {
+	var message strings.Builder
+	message.WriteString("Expected build participation to match the order group\n")
+	if len(m.missing) > 0 {
+		fmt.Fprintf(&message, "\tmissing:\n\t\t%s\n", strings.Join(m.missing, "\n\t\t"))
+	}
+	if len(m.unexpected) > 0 {
+		fmt.Fprintf(&message, "\tunexpected:\n\t\t%s\n", strings.Join(m.unexpected, "\n\t\t"))
+	}
+	fmt.Fprintf(&message, "in:\n%v", actual)
+
+	return message.String()
+}
+
+func (m *participationMatcher) NegatedFailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected build participation not to match the order group in:\n%v", actual)
+}
+
+// HaveBuildpack succeeds when the buildpack metadata of an occam.Image lists
+// the buildpack with the given id, wherever it appears in the order.
+func HaveBuildpack(id string) *BuildpackMatcher {
+	return &BuildpackMatcher{id: id}
+}
+
+// BuildpackMatcher is the matcher returned by HaveBuildpack.
+type BuildpackMatcher struct {
+	id       string
+	layer    string
+	metadata types.GomegaMatcher
+
+	found          bool
+	layerFound     bool
+	actualMetadata map[string]interface{}
+}
+
+// WithLayerMetadata additionally requires the buildpack to have contributed
+// the named layer with metadata satisfying the given matcher.
+func (m *BuildpackMatcher) WithLayerMetadata(layer string, matcher types.GomegaMatcher) *BuildpackMatcher {
+	m.layer = layer
+	m.metadata = matcher
+	return m
+}
+
+func (m *BuildpackMatcher) Match(actual interface{}) (bool, error) {
+	image, ok := actual.(occam.Image)
+	if !ok {
+		return false, fmt.Errorf("HaveBuildpack expects an occam.Image, got %T", actual)
+	}
+
+	m.found, m.layerFound, m.actualMetadata = false, false, nil
+	buildpack, ok := ImageBuildpack(image, m.id)
+	if !ok {
+		return false, nil
+	}
+	m.found = true
+
+	if m.metadata == nil {
+		return true, nil
+	}
+
+	layer, ok := buildpack.Layers[m.layer]
+	if !ok {
+		return false, nil
+	}
+	m.layerFound, m.actualMetadata = true, layer.Metadata
+
+	return m.metadata.Match(layer.Metadata)
+}
+
+func (m *BuildpackMatcher) FailureMessage(actual interface{}) string {
+	switch {
+	case !m.found:
+		return fmt.Sprintf("Expected image to contain buildpack %q, found:\n\t%s", m.id, strings.Join(imageBuildpackIDs(actual), "\n\t"))
+	case !m.layerFound:
+		return fmt.Sprintf("Expected buildpack %q to contribute layer %q", m.id, m.layer)
+	default:
+		return fmt.Sprintf("Expected layer %q of buildpack %q:\n%s", m.layer, m.id, m.metadata.FailureMessage(m.actualMetadata))
+	}
+}
+
+func (m *BuildpackMatcher) NegatedFailureMessage(actual interface{}) string {
+	if m.metadata == nil {
+		return fmt.Sprintf("Expected image not to contain buildpack %q", m.id)
+	}
+
+	return fmt.Sprintf("Expected layer %q of buildpack %q:\n%s", m.layer, m.id, m.metadata.NegatedFailureMessage(m.actualMetadata))
+}
+
+// ImageBuildpack returns the metadata the image records for the buildpack
+// with the given id.
+func ImageBuildpack(image occam.Image, id string) (occam.ImageBuildpackMetadata, bool) {
+	index := slices.IndexFunc(image.Buildpacks, func(buildpack occam.ImageBuildpackMetadata) bool {
+		return buildpack.Key == id
+	})
+	if index < 0 {
+		return occam.ImageBuildpackMetadata{}, false
+	}
+
+	return image.Buildpacks[index], true
+}
+
+func imageBuildpackIDs(actual interface{}) []string {
+	image, ok := actual.(occam.Image)
+	if !ok {
+		return nil
+	}
+
+	var ids []string
+	for _, buildpack := range image.Buildpacks {
+		ids = append(ids, buildpack.Key)
+	}
+
+	return ids
+}
+
+// HaveSBOMPackage succeeds when both the CycloneDX and the SPDX documents
+// generated by the given buildpack list the named package, as read by
+// ReadSBOMs.
+func HaveSBOMPackage(buildpack, name string) types.GomegaMatcher {
+	return &sbomPackageMatcher{
+		buildpack: buildpack,
+		name:      name,
+	}
+}
+
+type sbomPackageMatcher struct {
+	buildpack string
+	name      string
+
+	missing []string
+}
+
+func (m *sbomPackageMatcher) Match(actual interface{}) (bool, error) {
+	packages, ok := actual.([]SBOMPackage)
+	if !ok {
+		return false, fmt.Errorf("HaveSBOMPackage expects a []harness.SBOMPackage, got %T", actual)
+	}
+
+	m.missing = nil
+	for _, format := range []string{"cyclonedx", "spdx"} {
+		found := slices.ContainsFunc(packages, func(p SBOMPackage) bool {
+			return p.Buildpack == m.buildpack && p.Format == format && p.Is(m.name)
+		})
+
+		if !found {
+			m.missing = append(m.missing, format)
+		}
+	}
+
+	return len(m.missing) == 0, nil
+}
+
+func (m *sbomPackageMatcher) FailureMessage(actual interface{}) string {
+	var listed []string
+	if packages, ok := actual.([]SBOMPackage); ok {
+		for _, p := range packages {
+			if p.Buildpack == m.buildpack {
+				listed = append(listed, fmt.Sprintf("%s: %s %s (%s)", p.Format, p.Name, p.Version, p.PURL))
+			}
+		}
+	}
+
+	return fmt.Sprintf("Expected the %s SBOMs of buildpack %q to list package %q, found:\n\t%s",
+		strings.Join(m.missing, " and "), m.buildpack, m.name, strings.Join(listed, "\n\t"))
+}
+
+func (m *sbomPackageMatcher) NegatedFailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected the SBOMs of buildpack %q not to list package %q", m.buildpack, m.name)
+}
@@ -0,0 +1,169 @@
+package harness_test
+
+import (
+	"testing"
+
+	"github.com/paketo-buildpacks/occam"
+	"github.com/paketo-buildpacks/php/integration/internal/harness"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testHaveParticipated(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		order harness.Order
+		image occam.Image
+	)
+
+	it.Before(func() {
+		order = harness.Order{
+			Group: []harness.Buildpack{
+				{ID: "paketo-buildpacks/php-dist", Version: "1.2.3"},
+				{ID: "paketo-buildpacks/composer", Version: "2.3.4", Optional: true},
+				{ID: "paketo-buildpacks/composer-install", Version: "3.4.5", Optional: true},
+				{ID: "paketo-buildpacks/php-builtin-server", Version: "4.5.6"},
+			},
+		}
+
+		image = occam.Image{
+			Buildpacks: []occam.ImageBuildpackMetadata{
+				{Key: "paketo-buildpacks/php-dist"},
+				{Key: "paketo-buildpacks/composer-install"},
+				{Key: "paketo-buildpacks/php-builtin-server"},
+			},
+		}
+	})
+
+	it("matches when the required and the given optional buildpacks participated", func() {
+		Expect(image).To(harness.HaveParticipated(order, "paketo-buildpacks/composer-install"))
+	})
+
+	it("does not match when a given optional buildpack did not participate", func() {
+		matcher := harness.HaveParticipated(order, "paketo-buildpacks/composer", "paketo-buildpacks/composer-install")
+
+		match, err := matcher.Match(image)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(match).To(BeFalse())
+		Expect(matcher.FailureMessage(image)).To(ContainSubstring("missing:\n\t\tpaketo-buildpacks/composer\n"))
+	})
+
+	it("does not match when an optional buildpack participated unexpectedly", func() {
+		matcher := harness.HaveParticipated(order)
+
+		match, err := matcher.Match(image)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(match).To(BeFalse())
+		Expect(matcher.FailureMessage(image)).To(ContainSubstring("unexpected:\n\t\tpaketo-buildpacks/composer-install"))
+	})
+
+	it("does not match when a required buildpack did not participate", func() {
+		image.Buildpacks = image.Buildpacks[:1]
+
+		Expect(image).NotTo(harness.HaveParticipated(order))
+	})
+
+	it("does not match when a buildpack outside of the order group participated", func() {
+		image.Buildpacks = append(image.Buildpacks, occam.ImageBuildpackMetadata{Key: "paketo-buildpacks/procfile"})
+
+		matcher := harness.HaveParticipated(order, "paketo-buildpacks/composer-install")
+
+		match, err := matcher.Match(image)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(match).To(BeFalse())
+		Expect(matcher.FailureMessage(image)).To(ContainSubstring("unexpected:\n\t\tpaketo-buildpacks/procfile"))
+	})
+
+	context("failure cases", func() {
+		context("when the given optional buildpack is not part of the order group", func() {
+			it("returns an error", func() {
+				_, err := harness.HaveParticipated(order, "paketo-buildpacks/procfile").Match(image)
+				Expect(err).To(MatchError(`buildpack "paketo-buildpacks/procfile" is not part of the order group`))
+			})
+		})
+
+		context("when the actual value is not an image", func() {
+			it("returns an error", func() {
+				_, err := harness.HaveParticipated(order).Match("logs")
+				Expect(err).To(MatchError("HaveParticipated expects an occam.Image, got string"))
+			})
+		})
+	})
+}
+
+func testHaveBuildpack(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		image occam.Image
+	)
+
+	it.Before(func() {
+		image = occam.Image{
+			Buildpacks: []occam.ImageBuildpackMetadata{
+				{Key: "paketo-buildpacks/php-dist"},
+				{
+					Key: "paketo-buildpacks/environment-variables",
+					Layers: map[string]occam.ImageBuildpackMetadataLayer{
+						"environment-variables": {
+							Metadata: map[string]interface{}{
+								"variables": map[string]interface{}{"SOME_VARIABLE": "stew-peas"},
+							},
+						},
+					},
+				},
+			},
+		}
+	})
+
+	it("finds the metadata of a buildpack by id", func() {
+		buildpack, ok := harness.ImageBuildpack(image, "paketo-buildpacks/environment-variables")
+		Expect(ok).To(BeTrue())
+		Expect(buildpack.Layers).To(HaveKey("environment-variables"))
+
+		_, ok = harness.ImageBuildpack(image, "paketo-buildpacks/procfile")
+		Expect(ok).To(BeFalse())
+	})
+
+	it("matches when the image lists the buildpack", func() {
+		Expect(image).To(harness.HaveBuildpack("paketo-buildpacks/environment-variables"))
+		Expect(image).NotTo(harness.HaveBuildpack("paketo-buildpacks/procfile"))
+	})
+
+	it("matches the metadata of a layer of the buildpack", func() {
+		Expect(image).To(harness.HaveBuildpack("paketo-buildpacks/environment-variables").
+			WithLayerMetadata("environment-variables", HaveKeyWithValue("variables", map[string]interface{}{"SOME_VARIABLE": "stew-peas"})))
+		Expect(image).NotTo(harness.HaveBuildpack("paketo-buildpacks/environment-variables").
+			WithLayerMetadata("environment-variables", HaveKeyWithValue("variables", map[string]interface{}{"SOME_VARIABLE": "fish-n-chips"})))
+	})
+
+	it("does not match when the buildpack did not contribute the layer", func() {
+		matcher := harness.HaveBuildpack("paketo-buildpacks/php-dist").
+			WithLayerMetadata("php", HaveKey("version"))
+
+		match, err := matcher.Match(image)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(match).To(BeFalse())
+		Expect(matcher.FailureMessage(image)).To(Equal(`Expected buildpack "paketo-buildpacks/php-dist" to contribute layer "php"`))
+	})
+
+	it("lists the buildpacks of the image when the buildpack is missing", func() {
+		matcher := harness.HaveBuildpack("paketo-buildpacks/procfile")
+
+		match, err := matcher.Match(image)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(match).To(BeFalse())
+		Expect(matcher.FailureMessage(image)).To(ContainSubstring("paketo-buildpacks/php-dist\n\tpaketo-buildpacks/environment-variables"))
+	})
+
+	context("failure cases", func() {
+		context("when the actual value is not an image", func() {
+			it("returns an error", func() {
+				_, err := harness.HaveBuildpack("paketo-buildpacks/php-dist").Match("logs")
+				Expect(err).To(MatchError("HaveBuildpack expects an occam.Image, got string"))
+			})
+		})
+	})
+}
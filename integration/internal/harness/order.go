@@ -0,0 +1,66 @@
+package harness
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Buildpack is a single entry of an order group in buildpack.toml.
+type Buildpack struct {
+	ID       string `toml:"id"`
+	Version  string `toml:"version"`
+	Optional bool   `toml:"optional"`
+}
+
+// Order is an [[order]] table of buildpack.toml.
+type Order struct {
+	Group []Buildpack `toml:"group"`
+}
+
+// Buildpack returns the group entry with the given id.
+func (o Order) Buildpack(id string) (Buildpack, bool) {
+	for _, buildpack := range o.Group {
+		if buildpack.ID == id {
+			return buildpack, true
+		}
+	}
+
+	return Buildpack{}, false
+}
+
+// Orders are all of the order groups of a composite buildpack.
+type Orders []Order
+
+// Containing returns the order whose group includes the buildpack with the
+// given id. Every order group of this composite includes exactly one web
+// server buildpack, so that id identifies the group.
+func (o Orders) Containing(id string) (Order, error) {
+	for _, order := range o {
+		if _, ok := order.Buildpack(id); ok {
+			return order, nil
+		}
+	}
+
+	return Order{}, fmt.Errorf("no order group contains buildpack %q", id)
+}
+
+// ParseOrders reads the order groups from the buildpack.toml at path.
+func ParseOrders(path string) (Orders, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open buildpack.toml: %w", err)
+	}
+	defer file.Close()
+
+	var config struct {
+		Order Orders `toml:"order"`
+	}
+	_, err = toml.NewDecoder(file).Decode(&config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode buildpack.toml: %w", err)
+	}
+
+	return config.Order, nil
+}
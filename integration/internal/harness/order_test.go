@@ -0,0 +1,115 @@
+package harness_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/php/integration/internal/harness"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testOrders(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		path string
+	)
+
+	it.Before(func() {
+		path = filepath.Join(t.TempDir(), "buildpack.toml")
+		Expect(os.WriteFile(path, []byte(`api = "0.7"
+
+[[order]]
+
+  [[order.group]]
+    id = "paketo-buildpacks/php-dist"
+    version = "1.2.3"
+
+  [[order.group]]
+    id = "paketo-buildpacks/php-httpd"
+    version = "4.5.6"
+
+  [[order.group]]
+    id = "paketo-buildpacks/procfile"
+    optional = true
+    version = "7.8.9"
+
+[[order]]
+
+  [[order.group]]
+    id = "paketo-buildpacks/php-dist"
+    version = "1.2.3"
+
+  [[order.group]]
+    id = "paketo-buildpacks/php-nginx"
+    version = "3.2.1"
+`), 0600)).To(Succeed())
+	})
+
+	context("ParseOrders", func() {
+		it("reads every order group", func() {
+			orders, err := harness.ParseOrders(path)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(orders).To(Equal(harness.Orders{
+				{
+					Group: []harness.Buildpack{
+						{ID: "paketo-buildpacks/php-dist", Version: "1.2.3"},
+						{ID: "paketo-buildpacks/php-httpd", Version: "4.5.6"},
+						{ID: "paketo-buildpacks/procfile", Version: "7.8.9", Optional: true},
+					},
+				},
+				{
+					Group: []harness.Buildpack{
+						{ID: "paketo-buildpacks/php-dist", Version: "1.2.3"},
+						{ID: "paketo-buildpacks/php-nginx", Version: "3.2.1"},
+					},
+				},
+			}))
+		})
+
+		context("failure cases", func() {
+			context("when the file does not exist", func() {
+				it("returns an error", func() {
+					_, err := harness.ParseOrders(filepath.Join(t.TempDir(), "missing.toml"))
+					Expect(err).To(MatchError(ContainSubstring("failed to open buildpack.toml")))
+				})
+			})
+
+			context("when the file is not valid TOML", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(path, []byte("%%%"), 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := harness.ParseOrders(path)
+					Expect(err).To(MatchError(ContainSubstring("failed to decode buildpack.toml")))
+				})
+			})
+		})
+	})
+
+	context("Containing", func() {
+		it("returns the order group that includes the buildpack", func() {
+			orders, err := harness.ParseOrders(path)
+			Expect(err).NotTo(HaveOccurred())
+
+			order, err := orders.Containing("paketo-buildpacks/php-nginx")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(order).To(Equal(orders[1]))
+		})
+
+		context("when no order group includes the buildpack", func() {
+			it("returns an error", func() {
+				orders, err := harness.ParseOrders(path)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = orders.Containing("paketo-buildpacks/php-builtin-server")
+				Expect(err).To(MatchError(`no order group contains buildpack "paketo-buildpacks/php-builtin-server"`))
+			})
+		})
+	})
+}
@@ -0,0 +1,155 @@
+package harness
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SBOMPackage is a package listed in one of the CycloneDX or SPDX documents
+// that pack writes to its SBOM output directory.
+type SBOMPackage struct {
+	// Buildpack is the id of the buildpack that generated the document.
+	Buildpack string
+	// Format is either "cyclonedx" or "spdx".
+	Format  string
+	Name    string
+	Version string
+	PURL    string
+}
+
+// ReadSBOMs parses every CycloneDX and SPDX document below the SBOM output
+// directory of a build, which pack lays out as
+// sbom/<build|launch>/<escaped buildpack id>/[<layer>/]sbom.<format>.json.
+func ReadSBOMs(dir string) ([]SBOMPackage, error) {
+	root := filepath.Join(dir, "sbom")
+
+	var packages []SBOMPackage
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		var parse func(buildpack string, content []byte) ([]SBOMPackage, error)
+		switch entry.Name() {
+		case "sbom.cdx.json":
+			parse = parseCycloneDX
+		case "sbom.spdx.json":
+			parse = parseSPDX
+		default:
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		segments := strings.Split(filepath.ToSlash(rel), "/")
+		if len(segments) < 3 {
+			return nil
+		}
+		buildpack := strings.Replace(segments[1], "_", "/", 1)
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		found, err := parse(buildpack, content)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", rel, err)
+		}
+		packages = append(packages, found...)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return packages, nil
+}
+
+func parseCycloneDX(buildpack string, content []byte) ([]SBOMPackage, error) {
+	var document struct {
+		Components []struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+			PURL    string `json:"purl"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(content, &document); err != nil {
+		return nil, err
+	}
+
+	var packages []SBOMPackage
+	for _, component := range document.Components {
+		packages = append(packages, SBOMPackage{
+			Buildpack: buildpack,
+			Format:    "cyclonedx",
+			Name:      component.Name,
+			Version:   component.Version,
+			PURL:      component.PURL,
+		})
+	}
+
+	return packages, nil
+}
+
+func parseSPDX(buildpack string, content []byte) ([]SBOMPackage, error) {
+	var document struct {
+		Packages []struct {
+			Name         string `json:"name"`
+			VersionInfo  string `json:"versionInfo"`
+			ExternalRefs []struct {
+				ReferenceType    string `json:"referenceType"`
+				ReferenceLocator string `json:"referenceLocator"`
+			} `json:"externalRefs"`
+		} `json:"packages"`
+	}
+	if err := json.Unmarshal(content, &document); err != nil {
+		return nil, err
+	}
+
+	var packages []SBOMPackage
+	for _, p := range document.Packages {
+		pkg := SBOMPackage{
+			Buildpack: buildpack,
+			Format:    "spdx",
+			Name:      p.Name,
+			Version:   p.VersionInfo,
+		}
+
+		for _, ref := range p.ExternalRefs {
+			if ref.ReferenceType == "purl" {
+				pkg.PURL = ref.ReferenceLocator
+			}
+		}
+
+		packages = append(packages, pkg)
+	}
+
+	return packages, nil
+}
+
+// Is reports whether the package is the named one, going by its package URL
+// where it has one, e.g. "php" for pkg:generic/php@8.2.1 or "monolog/monolog"
+// for pkg:composer/monolog/monolog@1.25.1, and by its name otherwise.
+func (p SBOMPackage) Is(name string) bool {
+	if p.PURL != "" {
+		_, purl, ok := strings.Cut(p.PURL, "/")
+		if ok {
+			purl, _, _ = strings.Cut(purl, "@")
+			purl, _, _ = strings.Cut(purl, "?")
+			if strings.EqualFold(purl, name) {
+				return true
+			}
+		}
+	}
+
+	return strings.EqualFold(p.Name, name)
+}
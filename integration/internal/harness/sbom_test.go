@@ -0,0 +1,112 @@
+package harness_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/php/integration/internal/harness"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testSBOM(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		dir string
+	)
+
+	writeFile := func(path, content string) {
+		path = filepath.Join(dir, path)
+		Expect(os.MkdirAll(filepath.Dir(path), os.ModePerm)).To(Succeed())
+		Expect(os.WriteFile(path, []byte(content), 0644)).To(Succeed())
+	}
+
+	it.Before(func() {
+		dir = t.TempDir()
+
+		writeFile("sbom/launch/paketo-buildpacks_php-dist/php/sbom.cdx.json", `{
+  "bomFormat": "CycloneDX",
+  "components": [
+    {"name": "PHP", "version": "8.2.1", "purl": "pkg:generic/php@8.2.1?checksum=abc"}
+  ]
+}`)
+		writeFile("sbom/launch/paketo-buildpacks_php-dist/php/sbom.spdx.json", `{
+  "spdxVersion": "SPDX-2.2",
+  "packages": [
+    {
+      "name": "PHP",
+      "versionInfo": "8.2.1",
+      "externalRefs": [
+        {"referenceCategory": "PACKAGE_MANAGER", "referenceType": "purl", "referenceLocator": "pkg:generic/php@8.2.1"}
+      ]
+    }
+  ]
+}`)
+		writeFile("sbom/launch/paketo-buildpacks_php-dist/php/sbom.syft.json", `not parsed`)
+		writeFile("sbom/launch/paketo-buildpacks_composer-install/sbom.cdx.json", `{
+  "components": [
+    {"name": "monolog/monolog", "version": "1.25.1", "purl": "pkg:composer/monolog/monolog@1.25.1"}
+  ]
+}`)
+	})
+
+	context("ReadSBOMs", func() {
+		it("lists the packages of every CycloneDX and SPDX document by buildpack", func() {
+			packages, err := harness.ReadSBOMs(dir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(packages).To(ConsistOf(
+				harness.SBOMPackage{Buildpack: "paketo-buildpacks/composer-install", Format: "cyclonedx", Name: "monolog/monolog", Version: "1.25.1", PURL: "pkg:composer/monolog/monolog@1.25.1"},
+				harness.SBOMPackage{Buildpack: "paketo-buildpacks/php-dist", Format: "cyclonedx", Name: "PHP", Version: "8.2.1", PURL: "pkg:generic/php@8.2.1?checksum=abc"},
+				harness.SBOMPackage{Buildpack: "paketo-buildpacks/php-dist", Format: "spdx", Name: "PHP", Version: "8.2.1", PURL: "pkg:generic/php@8.2.1"},
+			))
+		})
+
+		context("failure cases", func() {
+			it("returns an error when there is no sbom directory", func() {
+				_, err := harness.ReadSBOMs(filepath.Join(dir, "missing"))
+				Expect(err).To(MatchError(ContainSubstring("no such file or directory")))
+			})
+
+			it("returns an error when a document is malformed", func() {
+				writeFile("sbom/build/paketo-buildpacks_composer/composer/sbom.spdx.json", `{`)
+
+				_, err := harness.ReadSBOMs(dir)
+				Expect(err).To(MatchError(ContainSubstring("failed to parse build/paketo-buildpacks_composer/composer/sbom.spdx.json")))
+			})
+		})
+	})
+
+	context("HaveSBOMPackage", func() {
+		var packages []harness.SBOMPackage
+
+		it.Before(func() {
+			var err error
+			packages, err = harness.ReadSBOMs(dir)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it("matches packages listed in both formats by package URL or name", func() {
+			Expect(packages).To(harness.HaveSBOMPackage("paketo-buildpacks/php-dist", "php"))
+			Expect(packages).NotTo(harness.HaveSBOMPackage("paketo-buildpacks/composer-install", "php"))
+		})
+
+		it("names the formats that do not list the package", func() {
+			matcher := harness.HaveSBOMPackage("paketo-buildpacks/composer-install", "monolog/monolog")
+
+			match, err := matcher.Match(packages)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(match).To(BeFalse())
+			Expect(matcher.FailureMessage(packages)).To(ContainSubstring(`Expected the spdx SBOMs of buildpack "paketo-buildpacks/composer-install" to list package "monolog/monolog"`))
+		})
+
+		context("failure cases", func() {
+			it("returns an error when the actual value is not a list of packages", func() {
+				_, err := harness.HaveSBOMPackage("paketo-buildpacks/php-dist", "php").Match("sbom")
+				Expect(err).To(MatchError("HaveSBOMPackage expects a []harness.SBOMPackage, got string"))
+			})
+		})
+	})
+}
@@ -0,0 +1,38 @@
+// Command session-store serves an in-memory key-value store over the Redis
+// and memcached protocols, standing in for both services in the session
+// handler integration tests.
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+	"time"
+
+	"github.com/paketo-buildpacks/php/integration/internal/sessionstore"
+)
+
+func main() {
+	redisAddress := flag.String("redis", ":6379", "address to serve the Redis protocol on")
+	memcachedAddress := flag.String("memcached", ":11211", "address to serve the memcached protocol on")
+	flag.Parse()
+
+	store := sessionstore.NewStore(time.Now)
+
+	redisListener, err := net.Listen("tcp", *redisAddress)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	memcachedListener, err := net.Listen("tcp", *memcachedAddress)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	errs := make(chan error, 2)
+	go func() { errs <- sessionstore.ServeRedis(redisListener, store) }()
+	go func() { errs <- sessionstore.ServeMemcached(memcachedListener, store) }()
+
+	log.Printf("serving Redis on %s and memcached on %s", redisListener.Addr(), memcachedListener.Addr())
+	log.Fatal(<-errs)
+}
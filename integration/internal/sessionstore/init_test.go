@@ -0,0 +1,31 @@
+package sessionstore_test
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestUnitSessionStore(t *testing.T) {
+	suite := spec.New("sessionstore", spec.Report(report.Terminal{}))
+	suite("Memcached", testMemcached)
+	suite("Redis", testRedis)
+	suite.Run(t)
+}
+
+// readLines reads the given number of CRLF terminated lines
+func readLines(reader *bufio.Reader, count int) string {
+	var lines strings.Builder
+	for range count {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return lines.String() + line
+		}
+		lines.WriteString(line)
+	}
+
+	return lines.String()
+}
@@ -0,0 +1,210 @@
+package sessionstore
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRelativeExpiration is the largest expiration time, in seconds, that
+// memcached treats as relative to now rather than as a Unix timestamp
+const maxRelativeExpiration = 60 * 60 * 24 * 30
+
+// memcachedVersion is the version the server reports
+const memcachedVersion = "1.6.0-sessionstore"
+
+// ServeMemcached answers the subset of the memcached protocol that the
+// php-memcached session handler uses. Like memcached, it picks the text or
+// the binary protocol for each connection from its first byte. Both support
+// get, set, add, replace, delete, touch, version and quit.
+func ServeMemcached(listener net.Listener, store *Store) error {
+	return serve(listener, func(reader *bufio.Reader, writer *bufio.Writer) error {
+		first, err := reader.Peek(1)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+
+		if first[0] == binaryRequestMagic {
+			return serveMemcachedBinary(store, reader, writer)
+		}
+
+		return serveMemcachedText(store, reader, writer)
+	})
+}
+
+func serveMemcachedText(store *Store, reader *bufio.Reader, writer *bufio.Writer) error {
+	for {
+		line, err := readLine(reader)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+
+		quit, err := handleMemcachedCommand(store, reader, writer, strings.Fields(line))
+		if err != nil {
+			return err
+		}
+
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+
+		if quit {
+			return nil
+		}
+	}
+}
+
+func handleMemcachedCommand(store *Store, reader *bufio.Reader, writer *bufio.Writer, fields []string) (bool, error) {
+	if len(fields) == 0 {
+		writer.WriteString("ERROR\r\n")
+		return false, nil
+	}
+
+	command, args := fields[0], fields[1:]
+
+	noreply := len(args) > 0 && args[len(args)-1] == "noreply"
+	if noreply {
+		args = args[:len(args)-1]
+	}
+
+	reply := func(response string) {
+		if !noreply {
+			writer.WriteString(response + "\r\n")
+		}
+	}
+
+	switch command {
+	case "get":
+		for _, key := range args {
+			i, ok := store.get(key)
+			if !ok {
+				continue
+			}
+			fmt.Fprintf(writer, "VALUE %s %d %d\r\n%s\r\n", key, i.flags, len(i.value), i.value)
+		}
+		writer.WriteString("END\r\n")
+
+	case "set", "add", "replace":
+		if len(args) != 4 {
+			writer.WriteString("ERROR\r\n")
+			return false, nil
+		}
+
+		flags, err := strconv.ParseUint(args[1], 10, 32)
+		if err != nil {
+			writer.WriteString("CLIENT_ERROR bad command line format\r\n")
+			return false, nil
+		}
+
+		exptime, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			writer.WriteString("CLIENT_ERROR bad command line format\r\n")
+			return false, nil
+		}
+
+		length, err := strconv.Atoi(args[3])
+		if err != nil || length < 0 {
+			writer.WriteString("CLIENT_ERROR bad command line format\r\n")
+			return false, nil
+		}
+
+		data := make([]byte, length+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return false, err
+		}
+
+		if string(data[length:]) != "\r\n" {
+			writer.WriteString("CLIENT_ERROR bad data chunk\r\n")
+			return false, nil
+		}
+
+		var condition func(exists bool) bool
+		switch command {
+		case "add":
+			condition = func(exists bool) bool { return !exists }
+		case "replace":
+			condition = func(exists bool) bool { return exists }
+		}
+
+		i := item{
+			value:     data[:length],
+			flags:     uint32(flags),
+			expiresAt: memcachedExpiration(store.now(), exptime),
+		}
+
+		if !store.set(args[0], i, condition) {
+			reply("NOT_STORED")
+			break
+		}
+		reply("STORED")
+
+	case "delete":
+		if len(args) != 1 {
+			writer.WriteString("ERROR\r\n")
+			return false, nil
+		}
+
+		if !store.delete(args[0]) {
+			reply("NOT_FOUND")
+			break
+		}
+		reply("DELETED")
+
+	case "touch":
+		if len(args) != 2 {
+			writer.WriteString("ERROR\r\n")
+			return false, nil
+		}
+
+		exptime, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			writer.WriteString("CLIENT_ERROR bad command line format\r\n")
+			return false, nil
+		}
+
+		if !store.expire(args[0], memcachedExpiration(store.now(), exptime)) {
+			reply("NOT_FOUND")
+			break
+		}
+		reply("TOUCHED")
+
+	case "version":
+		writer.WriteString("VERSION " + memcachedVersion + "\r\n")
+
+	case "quit":
+		return true, nil
+
+	default:
+		writer.WriteString("ERROR\r\n")
+	}
+
+	return false, nil
+}
+
+// memcachedExpiration converts a memcached expiration time into an absolute
+// time, where the zero time never expires
+func memcachedExpiration(now time.Time, exptime int64) time.Time {
+	switch {
+	case exptime == 0:
+		return time.Time{}
+	case exptime < 0:
+		return now
+	case exptime > maxRelativeExpiration:
+		return time.Unix(exptime, 0)
+	default:
+		return now.Add(time.Duration(exptime) * time.Second)
+	}
+}
@@ -0,0 +1,250 @@
+package sessionstore
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	binaryRequestMagic  = 0x80
+	binaryResponseMagic = 0x81
+
+	binaryHeaderLength = 24
+)
+
+// Opcodes of the memcached binary protocol. The quiet variants do not reply
+// on success, except for getq and getkq, which do not reply on a miss.
+const (
+	opGet      = 0x00
+	opSet      = 0x01
+	opAdd      = 0x02
+	opReplace  = 0x03
+	opDelete   = 0x04
+	opQuit     = 0x07
+	opGetQ     = 0x09
+	opNoop     = 0x0a
+	opVersion  = 0x0b
+	opGetK     = 0x0c
+	opGetKQ    = 0x0d
+	opSetQ     = 0x11
+	opAddQ     = 0x12
+	opReplaceQ = 0x13
+	opDeleteQ  = 0x14
+	opQuitQ    = 0x17
+	opTouch    = 0x1c
+)
+
+// Response statuses of the memcached binary protocol
+const (
+	statusKeyNotFound     = 0x0001
+	statusKeyExists       = 0x0002
+	statusInvalidArgument = 0x0004
+	statusNotStored       = 0x0005
+	statusUnknownCommand  = 0x0081
+)
+
+var binaryStatusMessages = map[uint16]string{
+	statusKeyNotFound:     "Not found",
+	statusKeyExists:       "Data exists for key.",
+	statusInvalidArgument: "Invalid arguments",
+	statusNotStored:       "Not stored.",
+	statusUnknownCommand:  "Unknown command",
+}
+
+type binaryRequest struct {
+	opcode byte
+	opaque uint32
+	extras []byte
+	key    string
+	value  []byte
+}
+
+type binaryResponse struct {
+	status uint16
+	extras []byte
+	key    string
+	value  []byte
+}
+
+func serveMemcachedBinary(store *Store, reader *bufio.Reader, writer *bufio.Writer) error {
+	for {
+		request, err := readBinaryRequest(reader)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+
+		response, reply, quit := handleMemcachedBinaryRequest(store, request)
+		if reply {
+			writeBinaryResponse(writer, request, response)
+		}
+
+		// Quiet requests are pipelined until a request that replies, so only
+		// flush once there is something to read
+		if reply || quit {
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+		}
+
+		if quit {
+			return nil
+		}
+	}
+}
+
+func readBinaryRequest(reader *bufio.Reader) (binaryRequest, error) {
+	header := make([]byte, binaryHeaderLength)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return binaryRequest{}, err
+	}
+
+	if header[0] != binaryRequestMagic {
+		return binaryRequest{}, fmt.Errorf("invalid request magic 0x%02x", header[0])
+	}
+
+	keyLength := int(binary.BigEndian.Uint16(header[2:4]))
+	extrasLength := int(header[4])
+	bodyLength := int(binary.BigEndian.Uint32(header[8:12]))
+	if keyLength+extrasLength > bodyLength {
+		return binaryRequest{}, fmt.Errorf("key and extras of %d bytes exceed body of %d bytes", keyLength+extrasLength, bodyLength)
+	}
+
+	body := make([]byte, bodyLength)
+	if _, err := io.ReadFull(reader, body); err != nil {
+		return binaryRequest{}, err
+	}
+
+	return binaryRequest{
+		opcode: header[1],
+		opaque: binary.BigEndian.Uint32(header[12:16]),
+		extras: body[:extrasLength],
+		key:    string(body[extrasLength : extrasLength+keyLength]),
+		value:  body[extrasLength+keyLength:],
+	}, nil
+}
+
+func writeBinaryResponse(writer *bufio.Writer, request binaryRequest, response binaryResponse) {
+	value := response.value
+	if message, ok := binaryStatusMessages[response.status]; ok && value == nil {
+		value = []byte(message)
+	}
+
+	header := make([]byte, binaryHeaderLength)
+	header[0] = binaryResponseMagic
+	header[1] = request.opcode
+	binary.BigEndian.PutUint16(header[2:4], uint16(len(response.key)))
+	header[4] = byte(len(response.extras))
+	binary.BigEndian.PutUint16(header[6:8], response.status)
+	binary.BigEndian.PutUint32(header[8:12], uint32(len(response.extras)+len(response.key)+len(value)))
+	binary.BigEndian.PutUint32(header[12:16], request.opaque)
+
+	writer.Write(header)
+	writer.Write(response.extras)
+	writer.WriteString(response.key)
+	writer.Write(value)
+}
+
+// handleMemcachedBinaryRequest returns the response to the request, whether
+// to send it and whether to close the connection afterwards. CAS values are
+// neither checked nor returned, as the session handler does not use them.
+func handleMemcachedBinaryRequest(store *Store, request binaryRequest) (binaryResponse, bool, bool) {
+	switch request.opcode {
+	case opGet, opGetQ, opGetK, opGetKQ:
+		quiet := request.opcode == opGetQ || request.opcode == opGetKQ
+		withKey := request.opcode == opGetK || request.opcode == opGetKQ
+
+		var key string
+		if withKey {
+			key = request.key
+		}
+
+		if len(request.extras) != 0 || request.key == "" {
+			return binaryResponse{status: statusInvalidArgument}, true, false
+		}
+
+		i, ok := store.get(request.key)
+		if !ok {
+			return binaryResponse{status: statusKeyNotFound, key: key}, !quiet, false
+		}
+
+		extras := binary.BigEndian.AppendUint32(nil, i.flags)
+		return binaryResponse{extras: extras, key: key, value: i.value}, true, false
+
+	case opSet, opSetQ, opAdd, opAddQ, opReplace, opReplaceQ:
+		quiet := request.opcode == opSetQ || request.opcode == opAddQ || request.opcode == opReplaceQ
+
+		if len(request.extras) != 8 || request.key == "" {
+			return binaryResponse{status: statusInvalidArgument}, true, false
+		}
+
+		var (
+			condition func(exists bool) bool
+			failure   uint16 = statusNotStored
+		)
+		switch request.opcode {
+		case opAdd, opAddQ:
+			condition = func(exists bool) bool { return !exists }
+			failure = statusKeyExists
+		case opReplace, opReplaceQ:
+			condition = func(exists bool) bool { return exists }
+			failure = statusKeyNotFound
+		}
+
+		i := item{
+			value:     request.value,
+			flags:     binary.BigEndian.Uint32(request.extras[0:4]),
+			expiresAt: memcachedExpiration(store.now(), int64(int32(binary.BigEndian.Uint32(request.extras[4:8])))),
+		}
+
+		if !store.set(request.key, i, condition) {
+			return binaryResponse{status: failure}, true, false
+		}
+
+		return binaryResponse{}, !quiet, false
+
+	case opDelete, opDeleteQ:
+		if len(request.extras) != 0 || request.key == "" {
+			return binaryResponse{status: statusInvalidArgument}, true, false
+		}
+
+		if !store.delete(request.key) {
+			return binaryResponse{status: statusKeyNotFound}, true, false
+		}
+
+		return binaryResponse{}, request.opcode == opDelete, false
+
+	case opTouch:
+		if len(request.extras) != 4 || request.key == "" {
+			return binaryResponse{status: statusInvalidArgument}, true, false
+		}
+
+		exptime := int64(int32(binary.BigEndian.Uint32(request.extras)))
+		if !store.expire(request.key, memcachedExpiration(store.now(), exptime)) {
+			return binaryResponse{status: statusKeyNotFound}, true, false
+		}
+
+		return binaryResponse{}, true, false
+
+	case opNoop:
+		return binaryResponse{}, true, false
+
+	case opVersion:
+		return binaryResponse{value: []byte(memcachedVersion)}, true, false
+
+	case opQuit:
+		return binaryResponse{}, true, true
+
+	case opQuitQ:
+		return binaryResponse{}, false, true
+
+	default:
+		return binaryResponse{status: statusUnknownCommand}, true, false
+	}
+}
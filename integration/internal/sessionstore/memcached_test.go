@@ -0,0 +1,275 @@
+package sessionstore_test
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/paketo-buildpacks/php/integration/internal/sessionstore"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testMemcached(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		now      time.Time
+		listener net.Listener
+		conn     net.Conn
+		reader   *bufio.Reader
+		done     chan error
+	)
+
+	// send writes a raw command and returns the reply, which has the given
+	// number of lines
+	send := func(lines int, command string) string {
+		_, err := io.WriteString(conn, command)
+		Expect(err).NotTo(HaveOccurred())
+
+		return readLines(reader, lines)
+	}
+
+	it.Before(func() {
+		now = time.Unix(1700000000, 0)
+		store := sessionstore.NewStore(func() time.Time { return now })
+
+		var err error
+		listener, err = net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+
+		done = make(chan error, 1)
+		go func() { done <- sessionstore.ServeMemcached(listener, store) }()
+
+		conn, err = net.Dial("tcp", listener.Addr().String())
+		Expect(err).NotTo(HaveOccurred())
+		reader = bufio.NewReader(conn)
+	})
+
+	it.After(func() {
+		Expect(conn.Close()).To(Succeed())
+		Expect(listener.Close()).To(Succeed())
+		Expect(<-done).To(Succeed())
+	})
+
+	it("stores, reads and deletes a session", func() {
+		Expect(send(1, "get memc.sess.key.abc\r\n")).To(Equal("END\r\n"))
+		Expect(send(1, "set memc.sess.key.abc 0 1440 10\r\ncount|i:2;\r\n")).To(Equal("STORED\r\n"))
+		Expect(send(3, "get memc.sess.key.abc\r\n")).To(Equal("VALUE memc.sess.key.abc 0 10\r\ncount|i:2;\r\nEND\r\n"))
+		Expect(send(1, "delete memc.sess.key.abc\r\n")).To(Equal("DELETED\r\n"))
+		Expect(send(1, "delete memc.sess.key.abc\r\n")).To(Equal("NOT_FOUND\r\n"))
+	})
+
+	it("returns every hit of a multi-key get", func() {
+		Expect(send(1, "set a 1 0 1\r\nA\r\n")).To(Equal("STORED\r\n"))
+		Expect(send(1, "set c 3 0 1\r\nC\r\n")).To(Equal("STORED\r\n"))
+		Expect(send(5, "get a b c\r\n")).To(Equal("VALUE a 1 1\r\nA\r\nVALUE c 3 1\r\nC\r\nEND\r\n"))
+	})
+
+	it("adds the session lock only when it is not held", func() {
+		Expect(send(1, "add lock.abc 0 30 1\r\n1\r\n")).To(Equal("STORED\r\n"))
+		Expect(send(1, "add lock.abc 0 30 1\r\n1\r\n")).To(Equal("NOT_STORED\r\n"))
+		Expect(send(1, "replace missing 0 30 1\r\n1\r\n")).To(Equal("NOT_STORED\r\n"))
+	})
+
+	it("expires items after their expiration time", func() {
+		Expect(send(1, "set session 0 10 4\r\ndata\r\n")).To(Equal("STORED\r\n"))
+
+		now = now.Add(9 * time.Second)
+		Expect(send(1, "touch session 10\r\n")).To(Equal("TOUCHED\r\n"))
+
+		now = now.Add(10 * time.Second)
+		Expect(send(1, "get session\r\n")).To(Equal("END\r\n"))
+		Expect(send(1, "touch session 10\r\n")).To(Equal("NOT_FOUND\r\n"))
+	})
+
+	it("treats large expiration times as Unix timestamps", func() {
+		Expect(send(1, "set session 0 "+strconv.FormatInt(now.Add(time.Minute).Unix(), 10)+" 4\r\ndata\r\n")).To(Equal("STORED\r\n"))
+
+		now = now.Add(59 * time.Second)
+		Expect(send(3, "get session\r\n")).To(Equal("VALUE session 0 4\r\ndata\r\nEND\r\n"))
+
+		now = now.Add(time.Second)
+		Expect(send(1, "get session\r\n")).To(Equal("END\r\n"))
+	})
+
+	it("does not reply to noreply commands", func() {
+		Expect(send(1, "set session 0 0 4 noreply\r\ndata\r\nversion\r\n")).To(HavePrefix("VERSION "))
+	})
+
+	it("closes the connection on quit", func() {
+		_, err := io.WriteString(conn, "quit\r\n")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = reader.ReadByte()
+		Expect(err).To(MatchError(io.EOF))
+	})
+
+	context("when the client speaks the binary protocol", func() {
+		type response struct {
+			opcode byte
+			status uint16
+			opaque uint32
+			extras []byte
+			key    string
+			value  string
+		}
+
+		// request encodes a binary protocol request
+		request := func(opcode byte, opaque uint32, extras []byte, key, value string) []byte {
+			header := make([]byte, 24)
+			header[0] = 0x80
+			header[1] = opcode
+			binary.BigEndian.PutUint16(header[2:4], uint16(len(key)))
+			header[4] = byte(len(extras))
+			binary.BigEndian.PutUint32(header[8:12], uint32(len(extras)+len(key)+len(value)))
+			binary.BigEndian.PutUint32(header[12:16], opaque)
+
+			return append(append(append(header, extras...), key...), value...)
+		}
+
+		// exchange writes the requests and reads the given number of responses
+		exchange := func(responses int, requests ...[]byte) []response {
+			for _, r := range requests {
+				_, err := conn.Write(r)
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			var read []response
+			for range responses {
+				header := make([]byte, 24)
+				_, err := io.ReadFull(reader, header)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(header[0]).To(Equal(byte(0x81)))
+
+				extrasLength := int(header[4])
+				keyLength := int(binary.BigEndian.Uint16(header[2:4]))
+				body := make([]byte, binary.BigEndian.Uint32(header[8:12]))
+				_, err = io.ReadFull(reader, body)
+				Expect(err).NotTo(HaveOccurred())
+
+				read = append(read, response{
+					opcode: header[1],
+					status: binary.BigEndian.Uint16(header[6:8]),
+					opaque: binary.BigEndian.Uint32(header[12:16]),
+					extras: body[:extrasLength],
+					key:    string(body[extrasLength : extrasLength+keyLength]),
+					value:  string(body[extrasLength+keyLength:]),
+				})
+			}
+
+			return read
+		}
+
+		// storage returns the extras of a storage request
+		storage := func(flags, exptime uint32) []byte {
+			return binary.BigEndian.AppendUint32(binary.BigEndian.AppendUint32(nil, flags), exptime)
+		}
+
+		it("stores, reads and deletes a session", func() {
+			Expect(exchange(1, request(0x00, 1, nil, "memc.sess.key.abc", ""))).To(Equal([]response{
+				{opcode: 0x00, status: 0x0001, opaque: 1, extras: []byte{}, value: "Not found"},
+			}))
+
+			Expect(exchange(1, request(0x01, 2, storage(7, 1440), "memc.sess.key.abc", "count|i:2;"))).To(Equal([]response{
+				{opcode: 0x01, opaque: 2, extras: []byte{}},
+			}))
+
+			Expect(exchange(1, request(0x00, 3, nil, "memc.sess.key.abc", ""))).To(Equal([]response{
+				{opcode: 0x00, opaque: 3, extras: []byte{0, 0, 0, 7}, value: "count|i:2;"},
+			}))
+
+			Expect(exchange(1, request(0x04, 4, nil, "memc.sess.key.abc", ""))).To(Equal([]response{
+				{opcode: 0x04, opaque: 4, extras: []byte{}},
+			}))
+
+			Expect(exchange(1, request(0x04, 5, nil, "memc.sess.key.abc", ""))).To(Equal([]response{
+				{opcode: 0x04, status: 0x0001, opaque: 5, extras: []byte{}, value: "Not found"},
+			}))
+		})
+
+		it("returns the key with getk", func() {
+			exchange(1, request(0x01, 1, storage(0, 0), "session", "data"))
+
+			Expect(exchange(1, request(0x0c, 2, nil, "session", ""))).To(Equal([]response{
+				{opcode: 0x0c, opaque: 2, extras: []byte{0, 0, 0, 0}, key: "session", value: "data"},
+			}))
+		})
+
+		it("adds the session lock only when it is not held", func() {
+			Expect(exchange(1, request(0x02, 1, storage(0, 30), "lock.abc", "1"))[0].status).To(Equal(uint16(0x0000)))
+			Expect(exchange(1, request(0x02, 2, storage(0, 30), "lock.abc", "1"))[0].status).To(Equal(uint16(0x0002)))
+			Expect(exchange(1, request(0x03, 3, storage(0, 30), "missing", "1"))[0].status).To(Equal(uint16(0x0001)))
+		})
+
+		it("expires items after their expiration time and extends it with touch", func() {
+			exchange(1, request(0x01, 1, storage(0, 10), "session", "data"))
+
+			now = now.Add(9 * time.Second)
+			Expect(exchange(1, request(0x1c, 2, binary.BigEndian.AppendUint32(nil, 10), "session", ""))[0].status).To(Equal(uint16(0x0000)))
+
+			now = now.Add(9 * time.Second)
+			Expect(exchange(1, request(0x00, 3, nil, "session", ""))[0].value).To(Equal("data"))
+
+			now = now.Add(time.Second)
+			Expect(exchange(1, request(0x00, 4, nil, "session", ""))[0].status).To(Equal(uint16(0x0001)))
+			Expect(exchange(1, request(0x1c, 5, binary.BigEndian.AppendUint32(nil, 10), "session", ""))[0].status).To(Equal(uint16(0x0001)))
+		})
+
+		it("only replies to quiet requests when they have something to report", func() {
+			Expect(exchange(3,
+				request(0x11, 1, storage(0, 0), "a", "A"),
+				request(0x0d, 2, nil, "a", ""),
+				request(0x0d, 3, nil, "b", ""),
+				request(0x12, 4, storage(0, 0), "a", "A"),
+				request(0x0a, 5, nil, "", ""),
+			)).To(Equal([]response{
+				{opcode: 0x0d, opaque: 2, extras: []byte{0, 0, 0, 0}, key: "a", value: "A"},
+				{opcode: 0x12, status: 0x0002, opaque: 4, extras: []byte{}, value: "Data exists for key."},
+				{opcode: 0x0a, opaque: 5, extras: []byte{}},
+			}))
+		})
+
+		it("reports its version", func() {
+			Expect(exchange(1, request(0x0b, 1, nil, "", ""))[0].value).To(Equal("1.6.0-sessionstore"))
+		})
+
+		it("closes the connection on quit", func() {
+			Expect(exchange(1, request(0x07, 1, nil, "", ""))[0].opcode).To(Equal(byte(0x07)))
+
+			_, err := reader.ReadByte()
+			Expect(err).To(MatchError(io.EOF))
+		})
+
+		context("failure cases", func() {
+			it("rejects unknown opcodes", func() {
+				Expect(exchange(1, request(0x08, 1, nil, "", ""))).To(Equal([]response{
+					{opcode: 0x08, status: 0x0081, opaque: 1, extras: []byte{}, value: "Unknown command"},
+				}))
+			})
+
+			it("rejects storage requests without flags and expiration", func() {
+				Expect(exchange(1, request(0x01, 1, nil, "session", "data"))[0].status).To(Equal(uint16(0x0004)))
+			})
+		})
+	})
+
+	context("failure cases", func() {
+		it("rejects unknown commands", func() {
+			Expect(send(1, "flush_all\r\n")).To(Equal("ERROR\r\n"))
+		})
+
+		it("rejects malformed storage commands", func() {
+			Expect(send(1, "set session 0 later 4\r\n")).To(Equal("CLIENT_ERROR bad command line format\r\n"))
+		})
+
+		it("rejects data blocks that do not match their length", func() {
+			Expect(send(1, "set session 0 0 2\r\ndata\r\n")).To(Equal("CLIENT_ERROR bad data chunk\r\n"))
+		})
+	})
+}
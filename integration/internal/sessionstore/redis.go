@@ -0,0 +1,234 @@
+package sessionstore
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ServeRedis answers the subset of the Redis protocol (RESP) that the
+// phpredis session handler uses: PING, AUTH, SELECT, GET, SET, SETEX,
+// EXPIRE, EXISTS, DEL and QUIT.
+func ServeRedis(listener net.Listener, store *Store) error {
+	return serve(listener, func(reader *bufio.Reader, writer *bufio.Writer) error {
+		for {
+			args, err := readRESPCommand(reader)
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+
+				fmt.Fprintf(writer, "-ERR %s\r\n", err)
+				return writer.Flush()
+			}
+
+			quit := handleRedisCommand(store, writer, args)
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+
+			if quit {
+				return nil
+			}
+		}
+	})
+}
+
+// readRESPCommand reads a command sent as a RESP array of bulk strings, or
+// as an inline command
+func readRESPCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := readLine(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(line, "*") {
+		return strings.Fields(line), nil
+	}
+
+	count, err := strconv.Atoi(line[1:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid multibulk length %q", line[1:])
+	}
+
+	args := make([]string, 0, count)
+	for range count {
+		header, err := readLine(reader)
+		if err != nil {
+			return nil, err
+		}
+
+		if !strings.HasPrefix(header, "$") {
+			return nil, fmt.Errorf("expected '$', got %q", header)
+		}
+
+		length, err := strconv.Atoi(header[1:])
+		if err != nil || length < 0 {
+			return nil, fmt.Errorf("invalid bulk length %q", header[1:])
+		}
+
+		data := make([]byte, length+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+
+		args = append(args, string(data[:length]))
+	}
+
+	return args, nil
+}
+
+func handleRedisCommand(store *Store, writer *bufio.Writer, args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+
+	arity := map[string]int{
+		"PING":   1,
+		"AUTH":   2,
+		"SELECT": 2,
+		"GET":    2,
+		"SET":    3,
+		"SETEX":  4,
+		"EXPIRE": 3,
+		"EXISTS": 2,
+		"DEL":    2,
+		"QUIT":   1,
+	}
+
+	command := strings.ToUpper(args[0])
+	minimum, ok := arity[command]
+	if !ok {
+		fmt.Fprintf(writer, "-ERR unknown command '%s'\r\n", args[0])
+		return false
+	}
+
+	if len(args) < minimum {
+		fmt.Fprintf(writer, "-ERR wrong number of arguments for '%s' command\r\n", strings.ToLower(command))
+		return false
+	}
+
+	switch command {
+	case "PING":
+		writer.WriteString("+PONG\r\n")
+
+	case "AUTH", "SELECT":
+		writer.WriteString("+OK\r\n")
+
+	case "GET":
+		i, ok := store.get(args[1])
+		if !ok {
+			writer.WriteString("$-1\r\n")
+			break
+		}
+		fmt.Fprintf(writer, "$%d\r\n%s\r\n", len(i.value), i.value)
+
+	case "SET":
+		var (
+			expiresAt time.Time
+			condition func(exists bool) bool
+		)
+
+		for index := 3; index < len(args); index++ {
+			option := strings.ToUpper(args[index])
+			switch option {
+			case "NX":
+				condition = func(exists bool) bool { return !exists }
+			case "XX":
+				condition = func(exists bool) bool { return exists }
+			case "EX", "PX":
+				index++
+				if index == len(args) {
+					writer.WriteString("-ERR syntax error\r\n")
+					return false
+				}
+
+				ttl, err := strconv.Atoi(args[index])
+				if err != nil || ttl <= 0 {
+					writer.WriteString("-ERR invalid expire time in 'set' command\r\n")
+					return false
+				}
+
+				unit := time.Second
+				if option == "PX" {
+					unit = time.Millisecond
+				}
+				expiresAt = store.now().Add(time.Duration(ttl) * unit)
+			default:
+				writer.WriteString("-ERR syntax error\r\n")
+				return false
+			}
+		}
+
+		if !store.set(args[1], item{value: []byte(args[2]), expiresAt: expiresAt}, condition) {
+			writer.WriteString("$-1\r\n")
+			break
+		}
+		writer.WriteString("+OK\r\n")
+
+	case "SETEX":
+		ttl, err := strconv.Atoi(args[2])
+		if err != nil || ttl <= 0 {
+			writer.WriteString("-ERR invalid expire time in 'setex' command\r\n")
+			break
+		}
+
+		store.set(args[1], item{value: []byte(args[3]), expiresAt: store.now().Add(time.Duration(ttl) * time.Second)}, nil)
+		writer.WriteString("+OK\r\n")
+
+	case "EXPIRE":
+		ttl, err := strconv.Atoi(args[2])
+		if err != nil {
+			writer.WriteString("-ERR value is not an integer or out of range\r\n")
+			break
+		}
+
+		writeRESPBool(writer, store.expire(args[1], store.now().Add(time.Duration(ttl)*time.Second)))
+
+	case "EXISTS", "DEL":
+		var count int
+		for _, key := range args[1:] {
+			var ok bool
+			if command == "DEL" {
+				ok = store.delete(key)
+			} else {
+				_, ok = store.get(key)
+			}
+
+			if ok {
+				count++
+			}
+		}
+		fmt.Fprintf(writer, ":%d\r\n", count)
+
+	case "QUIT":
+		writer.WriteString("+OK\r\n")
+		return true
+	}
+
+	return false
+}
+
+func writeRESPBool(writer *bufio.Writer, value bool) {
+	if value {
+		writer.WriteString(":1\r\n")
+		return
+	}
+
+	writer.WriteString(":0\r\n")
+}
+
+// readLine reads a CRLF or LF terminated line without its terminator
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"), nil
+}
@@ -0,0 +1,129 @@
+package sessionstore_test
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/paketo-buildpacks/php/integration/internal/sessionstore"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testRedis(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		now      time.Time
+		listener net.Listener
+		conn     net.Conn
+		reader   *bufio.Reader
+		done     chan error
+	)
+
+	// send writes a RESP command and returns the raw reply, which has the
+	// given number of lines
+	send := func(lines int, args ...string) string {
+		command := "*" + strconv.Itoa(len(args)) + "\r\n"
+		for _, arg := range args {
+			command += "$" + strconv.Itoa(len(arg)) + "\r\n" + arg + "\r\n"
+		}
+
+		_, err := io.WriteString(conn, command)
+		Expect(err).NotTo(HaveOccurred())
+
+		return readLines(reader, lines)
+	}
+
+	it.Before(func() {
+		now = time.Unix(1700000000, 0)
+		store := sessionstore.NewStore(func() time.Time { return now })
+
+		var err error
+		listener, err = net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+
+		done = make(chan error, 1)
+		go func() { done <- sessionstore.ServeRedis(listener, store) }()
+
+		conn, err = net.Dial("tcp", listener.Addr().String())
+		Expect(err).NotTo(HaveOccurred())
+		reader = bufio.NewReader(conn)
+	})
+
+	it.After(func() {
+		Expect(conn.Close()).To(Succeed())
+		Expect(listener.Close()).To(Succeed())
+		Expect(<-done).To(Succeed())
+	})
+
+	it("answers the connection handshake", func() {
+		Expect(send(1, "AUTH", "secret")).To(Equal("+OK\r\n"))
+		Expect(send(1, "SELECT", "0")).To(Equal("+OK\r\n"))
+		Expect(send(1, "PING")).To(Equal("+PONG\r\n"))
+	})
+
+	it("stores, reads and deletes a session", func() {
+		Expect(send(1, "GET", "PHPREDIS_SESSION:abc")).To(Equal("$-1\r\n"))
+		Expect(send(1, "SETEX", "PHPREDIS_SESSION:abc", "1440", "count|i:2;")).To(Equal("+OK\r\n"))
+		Expect(send(2, "GET", "PHPREDIS_SESSION:abc")).To(Equal("$10\r\ncount|i:2;\r\n"))
+		Expect(send(1, "EXISTS", "PHPREDIS_SESSION:abc")).To(Equal(":1\r\n"))
+		Expect(send(1, "DEL", "PHPREDIS_SESSION:abc")).To(Equal(":1\r\n"))
+		Expect(send(1, "EXISTS", "PHPREDIS_SESSION:abc")).To(Equal(":0\r\n"))
+	})
+
+	it("expires sessions after their TTL", func() {
+		Expect(send(1, "SETEX", "session", "10", "data")).To(Equal("+OK\r\n"))
+
+		now = now.Add(9 * time.Second)
+		Expect(send(1, "EXPIRE", "session", "10")).To(Equal(":1\r\n"))
+
+		now = now.Add(9 * time.Second)
+		Expect(send(2, "GET", "session")).To(Equal("$4\r\ndata\r\n"))
+
+		now = now.Add(time.Second)
+		Expect(send(1, "GET", "session")).To(Equal("$-1\r\n"))
+		Expect(send(1, "EXPIRE", "session", "10")).To(Equal(":0\r\n"))
+	})
+
+	it("supports the options of SET used for session locks", func() {
+		Expect(send(1, "SET", "lock", "1", "NX", "PX", "500")).To(Equal("+OK\r\n"))
+		Expect(send(1, "SET", "lock", "1", "NX", "PX", "500")).To(Equal("$-1\r\n"))
+
+		now = now.Add(500 * time.Millisecond)
+		Expect(send(1, "SET", "lock", "1", "NX", "EX", "1")).To(Equal("+OK\r\n"))
+		Expect(send(1, "SET", "missing", "1", "XX")).To(Equal("$-1\r\n"))
+	})
+
+	it("accepts inline commands", func() {
+		_, err := io.WriteString(conn, "PING\r\n")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(readLines(reader, 1)).To(Equal("+PONG\r\n"))
+	})
+
+	it("closes the connection on QUIT", func() {
+		Expect(send(1, "QUIT")).To(Equal("+OK\r\n"))
+
+		_, err := reader.ReadByte()
+		Expect(err).To(MatchError(io.EOF))
+	})
+
+	context("failure cases", func() {
+		it("rejects unknown commands", func() {
+			Expect(send(1, "FLUSHALL")).To(Equal("-ERR unknown command 'FLUSHALL'\r\n"))
+		})
+
+		it("rejects commands with too few arguments", func() {
+			Expect(send(1, "SETEX", "session", "10")).To(Equal("-ERR wrong number of arguments for 'setex' command\r\n"))
+		})
+
+		it("rejects invalid expire times", func() {
+			Expect(send(1, "SETEX", "session", "0", "data")).To(Equal("-ERR invalid expire time in 'setex' command\r\n"))
+			Expect(send(1, "SET", "session", "data", "EX", "soon")).To(Equal("-ERR invalid expire time in 'set' command\r\n"))
+		})
+	})
+}
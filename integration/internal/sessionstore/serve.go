@@ -0,0 +1,30 @@
+package sessionstore
+
+import (
+	"bufio"
+	"errors"
+	"net"
+)
+
+// serve accepts connections until the listener is closed and answers each of
+// them on its own goroutine
+func serve(listener net.Listener, handle func(reader *bufio.Reader, writer *bufio.Writer) error) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+
+			return err
+		}
+
+		go func() {
+			defer conn.Close()
+
+			writer := bufio.NewWriter(conn)
+			_ = handle(bufio.NewReader(conn), writer)
+			_ = writer.Flush()
+		}()
+	}
+}
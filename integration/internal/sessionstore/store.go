@@ -0,0 +1,95 @@
+package sessionstore
+
+import (
+	"sync"
+	"time"
+)
+
+// Store is the in-memory key-value store shared by the Redis and memcached
+// protocol servers.
+type Store struct {
+	mutex sync.Mutex
+	items map[string]item
+	now   func() time.Time
+}
+
+type item struct {
+	value     []byte
+	flags     uint32
+	expiresAt time.Time
+}
+
+func (i item) expired(now time.Time) bool {
+	return !i.expiresAt.IsZero() && !now.Before(i.expiresAt)
+}
+
+// NewStore returns an empty Store that expires items according to the given
+// clock.
+func NewStore(now func() time.Time) *Store {
+	return &Store{
+		items: map[string]item{},
+		now:   now,
+	}
+}
+
+func (s *Store) get(key string) (item, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	i, ok := s.items[key]
+	if !ok {
+		return item{}, false
+	}
+
+	if i.expired(s.now()) {
+		delete(s.items, key)
+		return item{}, false
+	}
+
+	return i, true
+}
+
+// set stores the item when the condition, given whether the key currently
+// exists, holds, and reports whether it did
+func (s *Store) set(key string, i item, condition func(exists bool) bool) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	existing, ok := s.items[key]
+	if ok && existing.expired(s.now()) {
+		delete(s.items, key)
+		ok = false
+	}
+
+	if condition != nil && !condition(ok) {
+		return false
+	}
+
+	s.items[key] = i
+	return true
+}
+
+func (s *Store) expire(key string, expiresAt time.Time) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	i, ok := s.items[key]
+	if !ok || i.expired(s.now()) {
+		delete(s.items, key)
+		return false
+	}
+
+	i.expiresAt = expiresAt
+	s.items[key] = i
+	return true
+}
+
+func (s *Store) delete(key string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	i, ok := s.items[key]
+	delete(s.items, key)
+
+	return ok && !i.expired(s.now())
+}
@@ -0,0 +1,82 @@
+package integration_test
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/paketo-buildpacks/occam"
+	"github.com/paketo-buildpacks/php/integration/internal/harness"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+	. "github.com/paketo-buildpacks/occam/matchers"
+)
+
+func testMigrateProcess(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect     = NewWithT(t).Expect
+		Eventually = NewWithT(t).Eventually
+	)
+
+	for _, server := range matrixServers() {
+		context(fmt.Sprintf("building a PHP app with a Procfile migrate process using the %s server", server.name), func() {
+			var (
+				fixture           *harness.Fixture
+				postgresContainer occam.Container
+				ipAddress         string
+			)
+
+			it.Before(func() {
+				var err error
+				fixture, err = harness.NewFixture(phpBuildpack, filepath.Join("testdata", "migrate_app"))
+				Expect(err).NotTo(HaveOccurred())
+
+				postgresContainer, err = fixture.RunService(postgresImage, "5432", map[string]string{"POSTGRES_PASSWORD": "migrate-secret"})
+				Expect(err).NotTo(HaveOccurred())
+
+				ipAddress, err = postgresContainer.IPAddressForNetwork("bridge")
+				Expect(err).NotTo(HaveOccurred())
+
+				// The image restarts the server once initdb has run, and the first
+				// server only listens on the Unix socket, so poll over TCP to wait
+				// for the server that the app connects to
+				Eventually(func() error {
+					return exec.Command("docker", "exec", postgresContainer.ID, "pg_isready", "-h", "127.0.0.1", "-U", "postgres").Run()
+				}, 30*time.Second, time.Second).Should(Succeed())
+			})
+
+			it.After(func() {
+				Expect(fixture.Cleanup()).To(Succeed())
+			})
+
+			it("runs the migrations with the migrate process", func() {
+				image, logs, err := fixture.Build().
+					WithEnv(server.env).
+					Execute()
+				Expect(err).NotTo(HaveOccurred(), logs.String())
+
+				Expect(image).To(harness.HaveParticipated(server.order, server.optional("paketo-buildpacks/procfile")...))
+				Expect(logs).To(ContainLines(ContainSubstring("migrate: php bin/migrate.php")))
+
+				container, err := fixture.Run().
+					WithEntrypoint("migrate").
+					WithEnv(map[string]string{
+						"DATABASE_HOST":     ipAddress,
+						"DATABASE_PASSWORD": "migrate-secret",
+					}).
+					Execute(image)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(fixture.Logs(container)).Should(ContainSubstring("Migrations complete"))
+
+				output, err := exec.Command("docker", "exec", postgresContainer.ID, "psql", "-U", "postgres", "-tAc", "SELECT message FROM greetings").CombinedOutput()
+				Expect(err).NotTo(HaveOccurred(), string(output))
+				Expect(strings.TrimSpace(string(output))).To(Equal("hello"))
+			})
+		})
+	}
+}
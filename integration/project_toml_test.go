@@ -0,0 +1,187 @@
+package integration_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/paketo-buildpacks/occam"
+	"github.com/paketo-buildpacks/php/integration/internal/harness"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+	. "github.com/paketo-buildpacks/occam/matchers"
+)
+
+func testProjectToml(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect     = NewWithT(t).Expect
+		Eventually = NewWithT(t).Eventually
+	)
+
+	// projectToml renders a project.toml that sets the given build
+	// environment and adds the given io.buildpacks settings
+	projectToml := func(env map[string]string, buildpacks string) []byte {
+		var content strings.Builder
+		content.WriteString("[_]\nschema-version = \"0.2\"\n\n[io.buildpacks]\n")
+		content.WriteString(buildpacks)
+
+		for _, name := range slices.Sorted(maps.Keys(env)) {
+			fmt.Fprintf(&content, "\n[[io.buildpacks.build.env]]\nname = %q\nvalue = %q\n", name, env[name])
+		}
+
+		return []byte(content.String())
+	}
+
+	// listWorkspace lists the files of the app directory in the image
+	listWorkspace := func(image occam.Image) string {
+		output, err := exec.Command("docker", "run", "--rm", "--entrypoint", "ls", image.ID, "-A", "/workspace", "/workspace/htdocs").CombinedOutput()
+		Expect(err).NotTo(HaveOccurred(), string(output))
+
+		return string(output)
+	}
+
+	for _, server := range matrixServers() {
+		context(fmt.Sprintf("building a PHP app for the %s server configured only through project.toml", server.name), func() {
+			var fixture *harness.Fixture
+
+			it.Before(func() {
+				var err error
+				fixture, err = harness.NewFixture(phpBuildpack, filepath.Join("testdata", "simple_composer_app"))
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(os.WriteFile(filepath.Join(fixture.Source, "htdocs", "excluded.txt"), []byte("this file should not be in the image"), 0644)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(fixture.Source, "notes.txt"), []byte("this file is not included"), 0644)).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(fixture.Cleanup()).To(Succeed())
+			})
+
+			it("builds with the settings and honors the exclusions declared there", func() {
+				Expect(os.WriteFile(filepath.Join(fixture.Source, "project.toml"), projectToml(server.env, `exclude = ["htdocs/excluded.txt"]
+`), 0644)).To(Succeed())
+
+				image, logs, err := fixture.Build().Execute()
+				Expect(err).NotTo(HaveOccurred(), logs.String())
+
+				Expect(image).To(harness.HaveParticipated(server.order, server.optional(
+					"paketo-buildpacks/composer",
+					"paketo-buildpacks/composer-install",
+				)...))
+
+				container, err := fixture.Run().Execute(image)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(container).Should(Serve(ContainSubstring("SUCCESS: date loads.")).OnPort(8080).WithEndpoint("/index.php?date"))
+				Expect(container).NotTo(Serve(ContainSubstring("this file should not be in the image")).OnPort(8080).WithEndpoint("/excluded.txt"))
+
+				workspace := listWorkspace(image)
+				Expect(workspace).NotTo(ContainSubstring("excluded.txt"))
+				Expect(workspace).To(ContainSubstring("notes.txt"))
+			})
+
+			it("builds with the settings and honors the inclusions declared there", func() {
+				Expect(os.WriteFile(filepath.Join(fixture.Source, "project.toml"), projectToml(server.env, `include = ["htdocs/index.php", "composer.json", "composer.lock"]
+`), 0644)).To(Succeed())
+
+				image, logs, err := fixture.Build().Execute()
+				Expect(err).NotTo(HaveOccurred(), logs.String())
+
+				Expect(image).To(harness.HaveParticipated(server.order, server.optional(
+					"paketo-buildpacks/composer",
+					"paketo-buildpacks/composer-install",
+				)...))
+
+				container, err := fixture.Run().Execute(image)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(container).Should(Serve(ContainSubstring("SUCCESS: date loads.")).OnPort(8080).WithEndpoint("/index.php?date"))
+
+				workspace := listWorkspace(image)
+				Expect(workspace).To(ContainSubstring("index.php"))
+				Expect(workspace).NotTo(ContainSubstring("excluded.txt"))
+				Expect(workspace).NotTo(ContainSubstring("notes.txt"))
+				Expect(workspace).NotTo(ContainSubstring("README.md"))
+			})
+		})
+	}
+
+	context("building a PHP app that enables Nginx HTTPS through project.toml", func() {
+		var (
+			fixture *harness.Fixture
+			client  *http.Client
+		)
+
+		it.Before(func() {
+			var err error
+			fixture, err = harness.NewFixture(phpBuildpack, filepath.Join("testdata", "ca_cert_apps"))
+			Expect(err).NotTo(HaveOccurred())
+
+			caCert, err := os.ReadFile(fmt.Sprintf("%s/nginx_app/certs/ca.pem", fixture.Source))
+			Expect(err).ToNot(HaveOccurred())
+
+			caCertPool := x509.NewCertPool()
+			caCertPool.AppendCertsFromPEM(caCert)
+
+			cert, err := tls.LoadX509KeyPair(fmt.Sprintf("%s/nginx_app/certs/cert.pem", fixture.Source), fmt.Sprintf("%s/nginx_app/certs/key.pem", fixture.Source))
+			Expect(err).ToNot(HaveOccurred())
+
+			client = &http.Client{
+				Transport: &http.Transport{
+					TLSClientConfig: &tls.Config{
+						RootCAs:      caCertPool,
+						Certificates: []tls.Certificate{cert},
+						MinVersion:   tls.VersionTLS12,
+					},
+				},
+			}
+
+			Expect(os.WriteFile(filepath.Join(fixture.Source, "nginx_app", "project.toml"), []byte(`[_]
+schema-version = "0.2"
+
+[[io.buildpacks.build.env]]
+name = "BP_PHP_SERVER"
+value = "nginx"
+
+[[io.buildpacks.build.env]]
+name = "BP_PHP_NGINX_ENABLE_HTTPS"
+value = "true"
+`), 0644)).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(fixture.Cleanup()).To(Succeed())
+		})
+
+		it("serves the app over HTTPS", func() {
+			image, logs, err := fixture.Build().
+				WithPath("nginx_app").
+				Execute()
+			Expect(err).NotTo(HaveOccurred(), logs.String())
+
+			Expect(image).To(harness.HaveParticipated(nginxOrder,
+				"paketo-buildpacks/ca-certificates",
+			))
+
+			container, err := fixture.Run().
+				WithEnv(map[string]string{
+					"BP_PHP_ENABLE_HTTPS_REDIRECT": "false",
+					"SERVICE_BINDING_ROOT":         "/bindings",
+				}).
+				WithVolumes(fmt.Sprintf("%s:/bindings/ca-certificates", filepath.Join(fixture.Source, "binding"))).
+				Execute(image)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(container).Should(Serve(ContainSubstring("Hello world, Authenticated User!")).OnPort(8080).WithProtocol("https").WithEndpoint("/").WithClient(client))
+		})
+	})
+}
@@ -0,0 +1,140 @@
+package integration_test
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/occam"
+	"github.com/paketo-buildpacks/php/integration/internal/harness"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+	. "github.com/paketo-buildpacks/occam/matchers"
+)
+
+func testRebuild(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+	)
+
+	const (
+		phpLayer      = "/layers/paketo-buildpacks_php-dist/php"
+		composerLayer = "/layers/paketo-buildpacks_composer/composer"
+		vendorLayer   = "/layers/paketo-buildpacks_composer-install/composer-packages"
+	)
+
+	// layerSHA returns the digest the image records for a launch layer
+	layerSHA := func(image occam.Image, id, layer string) string {
+		buildpack, ok := harness.ImageBuildpack(image, id)
+		Expect(ok).To(BeTrue(), fmt.Sprintf("image does not list buildpack %q", id))
+		Expect(buildpack.Layers).To(HaveKey(layer))
+
+		return buildpack.Layers[layer].SHA
+	}
+
+	// dropMonolog rewrites composer.json and composer.lock so that the app only
+	// depends on psr/log, which changes the installed packages without
+	// needing network access to resolve a new lock file
+	dropMonolog := func(source string) {
+		Expect(os.WriteFile(filepath.Join(source, "composer.json"), []byte(`{
+    "name": "paketo-buildpacks/composer_app",
+    "type": "project",
+    "require": {
+        "psr/log": "^1.1"
+    }
+}
+`), 0644)).To(Succeed())
+
+		content, err := os.ReadFile(filepath.Join(source, "composer.lock"))
+		Expect(err).NotTo(HaveOccurred())
+
+		var lock map[string]interface{}
+		Expect(json.Unmarshal(content, &lock)).To(Succeed())
+
+		var packages []interface{}
+		for _, p := range lock["packages"].([]interface{}) {
+			if p.(map[string]interface{})["name"] != "monolog/monolog" {
+				packages = append(packages, p)
+			}
+		}
+		lock["packages"] = packages
+
+		// Composer hashes the keys of composer.json that affect resolution, as
+		// PHP's json_encode writes them, and warns when the lock does not match
+		lock["content-hash"] = fmt.Sprintf("%x", md5.Sum([]byte(`{"name":"paketo-buildpacks\/composer_app","require":{"psr\/log":"^1.1"}}`)))
+
+		content, err = json.MarshalIndent(lock, "", "    ")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(source, "composer.lock"), content, 0644)).To(Succeed())
+	}
+
+	for _, server := range matrixServers() {
+		context(fmt.Sprintf("rebuilding a PHP app with the %s server", server.name), func() {
+			var fixture *harness.Fixture
+
+			it.Before(func() {
+				var err error
+				fixture, err = harness.NewFixture(phpBuildpack, filepath.Join("testdata", "simple_composer_app"))
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			it.After(func() {
+				Expect(fixture.Cleanup()).To(Succeed())
+			})
+
+			it("reuses the PHP, Composer and vendor layers when only app code changes", func() {
+				first, logs, err := fixture.Build().
+					WithEnv(server.env).
+					Execute()
+				Expect(err).NotTo(HaveOccurred(), logs.String())
+
+				Expect(os.WriteFile(filepath.Join(fixture.Source, "htdocs", "rebuilt.php"), []byte("<?php echo 'rebuilt';"), 0644)).To(Succeed())
+
+				second, logs, err := fixture.Build().
+					WithEnv(server.env).
+					Execute()
+				Expect(err).NotTo(HaveOccurred(), logs.String())
+
+				Expect(second.ID).NotTo(Equal(first.ID))
+
+				Expect(logs).To(ContainLines(ContainSubstring(fmt.Sprintf("Reusing cached layer %s", phpLayer))))
+				Expect(logs).To(ContainLines(ContainSubstring(fmt.Sprintf("Reusing cached layer %s", composerLayer))))
+				Expect(logs).To(ContainLines(ContainSubstring(fmt.Sprintf("Reusing cached layer %s", vendorLayer))))
+
+				Expect(layerSHA(second, "paketo-buildpacks/php-dist", "php")).To(Equal(layerSHA(first, "paketo-buildpacks/php-dist", "php")))
+				Expect(layerSHA(second, "paketo-buildpacks/composer-install", "composer-packages")).To(Equal(layerSHA(first, "paketo-buildpacks/composer-install", "composer-packages")))
+
+				Expect(second).To(harness.HaveParticipated(server.order, server.optional(
+					"paketo-buildpacks/composer",
+					"paketo-buildpacks/composer-install",
+				)...))
+			})
+
+			it("rebuilds only the vendor layer when composer.lock changes", func() {
+				first, logs, err := fixture.Build().
+					WithEnv(server.env).
+					Execute()
+				Expect(err).NotTo(HaveOccurred(), logs.String())
+
+				dropMonolog(fixture.Source)
+
+				second, logs, err := fixture.Build().
+					WithEnv(server.env).
+					Execute()
+				Expect(err).NotTo(HaveOccurred(), logs.String())
+
+				Expect(logs.String()).NotTo(ContainSubstring("The lock file is not up to date"))
+				Expect(logs).To(ContainLines(ContainSubstring(fmt.Sprintf("Reusing cached layer %s", phpLayer))))
+				Expect(logs).To(ContainLines(ContainSubstring(fmt.Sprintf("Reusing cached layer %s", composerLayer))))
+				Expect(logs).NotTo(ContainLines(ContainSubstring(fmt.Sprintf("Reusing cached layer %s", vendorLayer))))
+
+				Expect(layerSHA(second, "paketo-buildpacks/php-dist", "php")).To(Equal(layerSHA(first, "paketo-buildpacks/php-dist", "php")))
+				Expect(layerSHA(second, "paketo-buildpacks/composer-install", "composer-packages")).NotTo(Equal(layerSHA(first, "paketo-buildpacks/composer-install", "composer-packages")))
+			})
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package integration_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/php/integration/internal/harness"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testSBOM(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+	)
+
+	for _, server := range matrixServers() {
+		context(fmt.Sprintf("building a PHP app with the %s server and an SBOM output directory", server.name), func() {
+			var (
+				fixture *harness.Fixture
+				sbomDir string
+			)
+
+			it.Before(func() {
+				var err error
+				fixture, err = harness.NewFixture(phpBuildpack, filepath.Join("testdata", "simple_composer_app"))
+				Expect(err).NotTo(HaveOccurred())
+
+				sbomDir = t.TempDir()
+			})
+
+			it.After(func() {
+				Expect(fixture.Cleanup()).To(Succeed())
+			})
+
+			it("writes CycloneDX and SPDX SBOMs for PHP, the web server and the Composer packages", func() {
+				image, logs, err := fixture.Build().
+					WithEnv(server.env).
+					WithSBOMOutputDir(sbomDir).
+					Execute()
+				Expect(err).NotTo(HaveOccurred(), logs.String())
+
+				Expect(image).To(harness.HaveParticipated(server.order, server.optional(
+					"paketo-buildpacks/composer",
+					"paketo-buildpacks/composer-install",
+				)...))
+
+				packages, err := harness.ReadSBOMs(sbomDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(packages).To(harness.HaveSBOMPackage("paketo-buildpacks/php-dist", "php"))
+
+				if server.webServer != "" {
+					Expect(packages).To(harness.HaveSBOMPackage(fmt.Sprintf("paketo-buildpacks/%s", server.webServer), server.webServer))
+				}
+
+				// every package of simple_composer_app/composer.lock
+				Expect(packages).To(harness.HaveSBOMPackage("paketo-buildpacks/composer-install", "monolog/monolog"))
+				Expect(packages).To(harness.HaveSBOMPackage("paketo-buildpacks/composer-install", "psr/log"))
+			})
+		})
+	}
+}
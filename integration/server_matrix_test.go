@@ -0,0 +1,510 @@
+package integration_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/cookiejar"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/paketo-buildpacks/php/integration/internal/harness"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+	. "github.com/paketo-buildpacks/occam/matchers"
+)
+
+// server describes how an app is built and run with one of the web servers,
+// and therefore with one of the order groups, of the PHP buildpack
+type server struct {
+	name  string
+	order harness.Order
+	env   map[string]string
+
+	// fpm is set when the server hands requests to PHP FPM
+	fpm bool
+
+	// webServer is the package, and the id of the buildpack providing it, of
+	// a web server that runs in front of PHP
+	webServer string
+
+	// procfile is a web process equivalent to the one the server starts by
+	// default
+	procfile string
+
+	// caCert describes how to build the matching ca_cert_apps fixture, if the
+	// server can terminate TLS
+	caCert *caCertApp
+
+	// pending maps the names of scenarios the server is not yet tested with
+	// to the reason why
+	pending map[string]string
+}
+
+type caCertApp struct {
+	path     string
+	buildEnv map[string]string
+	runEnv   map[string]string
+}
+
+// optional lists the optional buildpacks of the server's order group that
+// participate in every build, followed by the given ones
+func (s server) optional(ids ...string) []string {
+	var optional []string
+	if _, ok := s.order.Buildpack("paketo-buildpacks/ca-certificates"); ok {
+		optional = append(optional, "paketo-buildpacks/ca-certificates")
+	}
+
+	return append(optional, ids...)
+}
+
+// buildEnv returns the server's build environment together with the given
+// variables
+func (s server) buildEnv(env map[string]string) map[string]string {
+	merged := maps.Clone(s.env)
+	maps.Copy(merged, env)
+
+	return merged
+}
+
+// scenario registers the specs of one behaviour that every server must
+// support
+type scenario struct {
+	name string
+	run  func(t *testing.T, context spec.G, it spec.S, server server)
+}
+
+// matrixServers returns every server the integration suites build apps
+// with. It must be called once the order groups have been parsed.
+func matrixServers() []server {
+	return []server{
+		{
+			name:     "built-in",
+			order:    builtinOrder,
+			env:      map[string]string{"BP_PHP_WEB_DIR": "htdocs"},
+			procfile: `web: php -S 0.0.0.0:"${PORT:-80}" -t htdocs && echo hi`,
+			pending: map[string]string{
+				"CA certificates": "the built-in server does not terminate TLS",
+			},
+		},
+		{
+			name:      "HTTPD",
+			order:     httpdOrder,
+			env:       map[string]string{"BP_PHP_SERVER": "httpd"},
+			fpm:       true,
+			webServer: "httpd",
+			pending: map[string]string{
+				"Read-only filesystem": "php-fpm and php-httpd write the FPM socket, pid files and httpd runtime files below their layers, TMPDIR does not redirect them and the component buildpacks offer no writable path setting yet",
+			},
+			procfile: "web: php-fpm -y $PHP_FPM_PATH & httpd -f $PHP_HTTPD_PATH -k start -DFOREGROUND && echo hi",
+			caCert:   &caCertApp{path: "httpd_app"},
+		},
+		{
+			name:      "Nginx",
+			order:     nginxOrder,
+			env:       map[string]string{"BP_PHP_SERVER": "nginx"},
+			fpm:       true,
+			webServer: "nginx",
+			pending: map[string]string{
+				"Read-only filesystem": "php-fpm and php-nginx write the FPM socket, pid files and nginx temp directories below their layers, TMPDIR does not redirect them and the component buildpacks offer no writable path setting yet",
+			},
+			procfile: "web: procmgr-binary /layers/paketo-buildpacks_php-start/php-start/procs.yml && echo hi",
+			caCert: &caCertApp{
+				path:     "nginx_app",
+				buildEnv: map[string]string{"BP_PHP_NGINX_ENABLE_HTTPS": "true"},
+				runEnv:   map[string]string{"BP_PHP_ENABLE_HTTPS_REDIRECT": "false"},
+			},
+		},
+	}
+}
+
+func testServerMatrix(t *testing.T, context spec.G, it spec.S) {
+	scenarios := []scenario{
+		{name: "Basic", run: testServerBasic},
+		{name: "Utility buildpacks", run: testServerUtilityBuildpacks},
+		{name: "CA certificates", run: testServerCACertificates},
+		{
+			name: "Memcached session handler",
+			run: testServerSessionHandler(sessionHandler{
+				buildpack:   "paketo-buildpacks/php-memcached-session-handler",
+				port:        "11211",
+				binding:     "memcached_binding",
+				files:       []string{"host", "servers"},
+				saveHandler: "memcached",
+			}),
+		},
+		{
+			name: "Redis session handler",
+			run: testServerSessionHandler(sessionHandler{
+				buildpack:   "paketo-buildpacks/php-redis-session-handler",
+				port:        "6379",
+				binding:     "redis_binding",
+				files:       []string{"host"},
+				saveHandler: "redis",
+			}),
+		},
+		{name: "Reproducibility", run: testServerReproducibility},
+		{name: "Read-only filesystem", run: testServerReadOnlyFilesystem},
+	}
+
+	for _, server := range matrixServers() {
+		context(fmt.Sprintf("with the %s server", server.name), func() {
+			for _, scenario := range scenarios {
+				if reason, ok := server.pending[scenario.name]; ok {
+					context.Pend(scenario.name, func() {
+						it(reason, func() {})
+					})
+					continue
+				}
+
+				context(scenario.name, func() {
+					scenario.run(t, context, it, server)
+				})
+			}
+		})
+	}
+}
+
+func testServerBasic(t *testing.T, context spec.G, it spec.S, server server) {
+	var (
+		Expect     = NewWithT(t).Expect
+		Eventually = NewWithT(t).Eventually
+
+		fixture *harness.Fixture
+	)
+
+	it.Before(func() {
+		var err error
+		fixture, err = harness.NewFixture(phpBuildpack, filepath.Join("testdata", "simple_composer_app"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		Expect(fixture.Cleanup()).To(Succeed())
+	})
+
+	it("creates a working OCI image", func() {
+		image, logs, err := fixture.Build().
+			WithEnv(server.env).
+			Execute()
+		Expect(err).NotTo(HaveOccurred(), logs.String())
+
+		container, err := fixture.Run().Execute(image)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(container).Should(Serve(ContainSubstring("SUCCESS: date loads.")).OnPort(8080).WithEndpoint("/index.php?date"))
+
+		Expect(image).To(harness.HaveParticipated(server.order, server.optional(
+			"paketo-buildpacks/composer",
+			"paketo-buildpacks/composer-install",
+		)...))
+
+		if server.fpm {
+			Eventually(fixture.Logs(container)).Should(
+				And(
+					ContainSubstring("NOTICE: fpm is running"),
+					ContainSubstring("NOTICE: ready to handle connections"),
+				),
+			)
+		}
+	})
+}
+
+func testServerUtilityBuildpacks(t *testing.T, context spec.G, it spec.S, server server) {
+	var (
+		Expect     = NewWithT(t).Expect
+		Eventually = NewWithT(t).Eventually
+
+		fixture *harness.Fixture
+	)
+
+	it.Before(func() {
+		var err error
+		fixture, err = harness.NewFixture(phpBuildpack, filepath.Join("testdata", "simple_composer_app"))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.WriteFile(filepath.Join(fixture.Source, "Procfile"), []byte(server.procfile), 0644)).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(fixture.Cleanup()).To(Succeed())
+	})
+
+	it("creates a working OCI image and uses the Procfile, Environment Variables, and Image Labels buildpacks", func() {
+		env := map[string]string{
+			"BPE_SOME_VARIABLE": "stew-peas",
+			"BP_IMAGE_LABELS":   "cool-label=cool-value",
+		}
+		optional := []string{
+			"paketo-buildpacks/composer",
+			"paketo-buildpacks/composer-install",
+			"paketo-buildpacks/procfile",
+			"paketo-buildpacks/environment-variables",
+			"paketo-buildpacks/image-labels",
+		}
+
+		if _, ok := server.order.Buildpack("paketo-buildpacks/watchexec"); ok {
+			env["BP_LIVE_RELOAD_ENABLED"] = "true"
+			optional = append(optional, "paketo-buildpacks/watchexec")
+		}
+
+		image, logs, err := fixture.Build().
+			WithEnv(server.buildEnv(env)).
+			Execute()
+		Expect(err).NotTo(HaveOccurred(), logs.String())
+
+		container, err := fixture.Run().Execute(image)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(container).Should(Serve(ContainSubstring("SUCCESS: date loads.")).OnPort(8080).WithEndpoint("/index.php?date"))
+		Expect(logs).To(ContainLines(ContainSubstring(server.procfile)))
+
+		Expect(image).To(harness.HaveParticipated(server.order, server.optional(optional...)...))
+
+		Expect(image).To(harness.HaveBuildpack("paketo-buildpacks/environment-variables").
+			WithLayerMetadata("environment-variables", HaveKeyWithValue("variables", map[string]interface{}{"SOME_VARIABLE": "stew-peas"})))
+		Expect(image.Labels["cool-label"]).To(Equal("cool-value"))
+	})
+}
+
+func testServerCACertificates(t *testing.T, context spec.G, it spec.S, server server) {
+	var (
+		Expect     = NewWithT(t).Expect
+		Eventually = NewWithT(t).Eventually
+
+		fixture *harness.Fixture
+		client  *http.Client
+	)
+
+	it.Before(func() {
+		var err error
+		fixture, err = harness.NewFixture(phpBuildpack, filepath.Join("testdata", "ca_cert_apps"))
+		Expect(err).NotTo(HaveOccurred())
+
+		certs := filepath.Join(fixture.Source, server.caCert.path, "certs")
+
+		caCert, err := os.ReadFile(filepath.Join(certs, "ca.pem"))
+		Expect(err).ToNot(HaveOccurred())
+
+		caCertPool := x509.NewCertPool()
+		caCertPool.AppendCertsFromPEM(caCert)
+
+		cert, err := tls.LoadX509KeyPair(filepath.Join(certs, "cert.pem"), filepath.Join(certs, "key.pem"))
+		Expect(err).ToNot(HaveOccurred())
+
+		client = &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					RootCAs:      caCertPool,
+					Certificates: []tls.Certificate{cert},
+					MinVersion:   tls.VersionTLS12,
+				},
+			},
+		}
+	})
+
+	it.After(func() {
+		Expect(fixture.Cleanup()).To(Succeed())
+	})
+
+	it("builds a working OCI image with given CA cert added to trust store", func() {
+		image, logs, err := fixture.Build().
+			WithEnv(server.buildEnv(server.caCert.buildEnv)).
+			WithPath(server.caCert.path).
+			Execute()
+		Expect(err).NotTo(HaveOccurred(), logs.String())
+
+		Expect(image).To(harness.HaveParticipated(server.order, server.optional()...))
+
+		runEnv := map[string]string{"SERVICE_BINDING_ROOT": "/bindings"}
+		maps.Copy(runEnv, server.caCert.runEnv)
+
+		container, err := fixture.Run().
+			WithEnv(runEnv).
+			WithVolumes(fmt.Sprintf("%s:/bindings/ca-certificates", filepath.Join(fixture.Source, "binding"))).
+			Execute(image)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(fixture.Logs(container)).Should(
+			ContainSubstring("Added 1 additional CA certificate(s) to system truststore"),
+		)
+
+		Eventually(container).Should(Serve(ContainSubstring("Hello world, Authenticated User!")).OnPort(8080).WithProtocol("https").WithEndpoint("/").WithClient(client))
+	})
+}
+
+// sessionHandler describes the protocol of the session store stand-in that a
+// session handler buildpack talks to and the binding that points it there
+type sessionHandler struct {
+	buildpack   string
+	port        string
+	binding     string
+	files       []string
+	saveHandler string
+}
+
+func testServerSessionHandler(handler sessionHandler) func(t *testing.T, context spec.G, it spec.S, server server) {
+	return func(t *testing.T, context spec.G, it spec.S, server server) {
+		var (
+			Expect     = NewWithT(t).Expect
+			Eventually = NewWithT(t).Eventually
+
+			fixture *harness.Fixture
+			binding string
+		)
+
+		it.Before(func() {
+			var err error
+			fixture, err = harness.NewFixture(phpBuildpack, filepath.Join("testdata", "session_handler_apps"))
+			Expect(err).NotTo(HaveOccurred())
+
+			binding = filepath.Join(fixture.Source, handler.binding)
+
+			serviceContainer, err := fixture.RunService(sessionStoreImage, handler.port, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			ipAddress, err := serviceContainer.IPAddressForNetwork("bridge")
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, file := range handler.files {
+				Expect(os.WriteFile(filepath.Join(binding, file), []byte(ipAddress), os.ModePerm)).To(Succeed())
+			}
+		})
+
+		it.After(func() {
+			Expect(fixture.Cleanup()).To(Succeed())
+		})
+
+		env := server.buildEnv(map[string]string{
+			"BP_LOG_LEVEL":         "DEBUG",
+			"SERVICE_BINDING_ROOT": "/bindings",
+		})
+
+		// volume mounts the binding at the path its type names
+		volume := func() string {
+			bindingType, err := os.ReadFile(filepath.Join(binding, "type"))
+			Expect(err).NotTo(HaveOccurred())
+
+			return fmt.Sprintf("%s:/bindings/%s", binding, strings.TrimSpace(string(bindingType)))
+		}
+
+		it("creates a working OCI image that stores sessions with the handler", func() {
+			image, logs, err := fixture.Build().
+				WithEnv(env).
+				WithVolumes(volume()).
+				Execute()
+			Expect(err).NotTo(HaveOccurred(), logs.String())
+
+			container, err := fixture.Run().Execute(image)
+			Expect(err).NotTo(HaveOccurred())
+
+			jar, err := cookiejar.New(nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			client := &http.Client{
+				Jar: jar,
+			}
+
+			Eventually(container).Should(Serve(ContainSubstring("1")).WithClient(client).OnPort(8080).WithEndpoint("/index.php"))
+			Eventually(container).Should(Serve(ContainSubstring("2")).WithClient(client).OnPort(8080).WithEndpoint("/index.php"))
+
+			// The counter also works with the default file handler, so check
+			// that the PHP runtime behind the server loaded the session ini
+			sapi := "cli-server"
+			if server.fpm {
+				sapi = "fpm-fcgi"
+			}
+
+			Eventually(container).Should(Serve(And(
+				ContainSubstring(fmt.Sprintf("sapi: %s", sapi)),
+				ContainSubstring(fmt.Sprintf("session.save_handler: %s", handler.saveHandler)),
+			)).OnPort(8080).WithEndpoint("/handler.php"))
+
+			Expect(image).To(harness.HaveParticipated(server.order, server.optional(handler.buildpack)...))
+		})
+
+		it("creates a two identical images from the same input", func() {
+			first, second, err := buildTwice(fixture, env, volume())
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(first.ID).To(Equal(second.ID))
+
+			Expect(second).To(harness.HaveParticipated(server.order, server.optional(handler.buildpack)...))
+		})
+	}
+}
+
+func testServerReproducibility(t *testing.T, context spec.G, it spec.S, server server) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		fixture *harness.Fixture
+	)
+
+	it.Before(func() {
+		var err error
+		fixture, err = harness.NewFixture(phpBuildpack, filepath.Join("testdata", "simple_composer_app"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		Expect(fixture.Cleanup()).To(Succeed())
+	})
+
+	it("creates a two identical images from the same input", func() {
+		first, second, err := buildTwice(fixture, server.env)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(first.ID).To(Equal(second.ID))
+
+		Expect(second).To(harness.HaveParticipated(server.order, server.optional(
+			"paketo-buildpacks/composer",
+			"paketo-buildpacks/composer-install",
+		)...))
+	})
+}
+
+// readOnlyWritablePath is the only writable path of containers that run with
+// a read-only root filesystem. It is mounted as a tmpfs and handed to the app
+// as TMPDIR.
+var readOnlyWritablePath = "/tmp"
+
+func testServerReadOnlyFilesystem(t *testing.T, context spec.G, it spec.S, server server) {
+	var (
+		Expect     = NewWithT(t).Expect
+		Eventually = NewWithT(t).Eventually
+
+		fixture *harness.Fixture
+	)
+
+	it.Before(func() {
+		var err error
+		fixture, err = harness.NewFixture(phpBuildpack, filepath.Join("testdata", "simple_composer_app"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		Expect(fixture.Cleanup()).To(Succeed())
+	})
+
+	it("serves requests as an arbitrary user with a read-only root filesystem", func() {
+		image, logs, err := fixture.Build().
+			WithEnv(server.env).
+			Execute()
+		Expect(err).NotTo(HaveOccurred(), logs.String())
+
+		container, err := fixture.Run().
+			WithEnv(map[string]string{"TMPDIR": readOnlyWritablePath}).
+			WithUser("1234:0").
+			WithReadOnlyRoot(readOnlyWritablePath).
+			Execute(image)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(container).Should(Serve(ContainSubstring("SUCCESS: date loads.")).OnPort(8080).WithEndpoint("/index.php?date"))
+	})
+}
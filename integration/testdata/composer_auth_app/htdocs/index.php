@@ -0,0 +1,5 @@
+<?php
+    require '../vendor/autoload.php';
+
+    print Greeter\Greeter::greet();
+?>
@@ -0,0 +1,13 @@
+<?php
+    require '../vendor/autoload.php';
+
+    print Greeter\Greeter::greet() . ". ";
+    print Farewell\Farewell::wave() . ". ";
+
+    if (is_link(__DIR__ . '/../vendor/paketo-buildpacks/greeter')) {
+      print "greeter is symlinked.";
+    }
+    else {
+      print "greeter is mirrored.";
+    }
+?>
@@ -0,0 +1,10 @@
+<?php
+namespace Greeter;
+
+class Greeter
+{
+    public static function greet()
+    {
+        return "Hello from a path repository";
+    }
+}
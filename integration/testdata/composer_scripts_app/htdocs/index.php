@@ -0,0 +1,12 @@
+<?php
+    require '../vendor/autoload.php';
+
+    foreach (array('post-install-cmd', 'post-autoload-dump') as $script) {
+      if (file_exists(__DIR__ . '/../vendor/' . $script . '.marker')) {
+        echo $script . ': ran. ';
+      }
+      else {
+        echo $script . ': skipped. ';
+      }
+    }
+?>
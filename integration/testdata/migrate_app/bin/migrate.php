@@ -0,0 +1,9 @@
+<?php
+    $dsn = sprintf('pgsql:host=%s;port=5432;dbname=postgres', getenv('DATABASE_HOST'));
+    $db = new PDO($dsn, 'postgres', getenv('DATABASE_PASSWORD'), array(PDO::ATTR_ERRMODE => PDO::ERRMODE_EXCEPTION));
+
+    $db->exec('CREATE TABLE IF NOT EXISTS greetings (id SERIAL PRIMARY KEY, message TEXT NOT NULL)');
+    $db->exec("INSERT INTO greetings (message) VALUES ('hello')");
+
+    print "Migrations complete\n";
+?>
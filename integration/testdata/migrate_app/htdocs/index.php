@@ -0,0 +1,3 @@
+<?php
+    print "This is a PHP app with migrations.";
+?>
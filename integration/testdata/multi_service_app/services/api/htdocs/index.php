@@ -0,0 +1,3 @@
+<?php
+    print "This is the api service.";
+?>
@@ -0,0 +1,3 @@
+<?php
+    print "PHP " . PHP_VERSION;
+?>
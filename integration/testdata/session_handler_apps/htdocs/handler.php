@@ -0,0 +1,4 @@
+<?php
+// reports which session handler the PHP runtime serving the request picked up
+echo "sapi: " . php_sapi_name() . "\n";
+echo "session.save_handler: " . ini_get('session.save_handler') . "\n";